@@ -2,15 +2,30 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
 	"os"
 	"os/signal"
 	"robots/internal/conf"
+	"robots/pkg/clocks"
+	"robots/pkg/election"
 	"robots/pkg/errors"
 	"robots/pkg/events"
+	"robots/pkg/output"
 	"robots/pkg/robot"
+	"robots/pkg/snapshots"
+	"robots/pkg/termination"
+	"robots/pkg/topology"
+	"robots/pkg/transport"
 	"robots/pkg/workers"
+	pb "robots/proto"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/Netflix/go-env"
 	"github.com/mama165/sdk-go/logs"
@@ -26,6 +41,20 @@ func main() {
 		log.Error(err.Error())
 		panic(err)
 	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(config.Seed))
+	graph, err := topology.New(config, rng)
+	if err != nil {
+		log.Error(err.Error())
+		panic(err)
+	}
+	diameter := graph.Diameter()
+	log.Info(fmt.Sprintf("Topology %s with diameter %d (seed %d)", graph.Kind, diameter, config.Seed))
+	if diameter < 0 {
+		log.Warn("Topology is not connected, some robots will never receive the whole secret")
+	}
 
 	baseCtx := context.Background()
 	timeoutCtx, cancel := context.WithTimeout(baseCtx, config.Timeout)
@@ -34,50 +63,183 @@ func main() {
 	defer stop()
 	domainEvent := make(chan events.Event, config.BufferSize)
 	telemetryEvent := make(chan events.Event, config.BufferSize)
-	secretManager := robot.SecretManager{Config: config}
-	secret := secretManager.SplitSecret(config.Secret)
+	secretManager := robot.SecretManager{Config: config, Rand: rand.New(rand.NewSource(rng.Int63()))}
+	secretManager.Clocks = make([]*clocks.Physical, config.NbrOfRobots)
+	for i := range secretManager.Clocks {
+		secretManager.Clocks[i] = clocks.RandomPhysical(rng, config.ClockOffset, config.ClockDrift)
+	}
+	input, err := readSecret(config)
+	if err != nil {
+		log.Error(err.Error())
+		panic(err)
+	}
+	startedAt := time.Now()
+	secret := secretManager.SplitSecret(input)
+	if len(secret) == 0 {
+		log.Error(errors.ErrEmptySecret.Error())
+		panic(errors.ErrEmptySecret)
+	}
+	log.Info(fmt.Sprintf("Secret of %d bytes split into %d tokens", len(input), len(secret)))
 	robots := secretManager.CreateRobots(secret)
+	for _, r := range robots {
+		if r.Time != nil {
+			log.Debug(fmt.Sprintf("Robot %d clock has offset %s and drift %+.3f", r.ID, r.Time.Offset(), r.Time.Drift()))
+		}
+	}
+	// Other secrets are disseminated at once, through the same robots and channels
+	secrets := robot.Secrets{"": robots}
+	inputs, tokens := map[robot.SecretID]string{"": input}, map[robot.SecretID][]string{"": secret}
+	files, _ := robot.ParseSecretFiles(config.Secrets)
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			log.Error(err.Error())
+			panic(err)
+		}
+		inputs[file.ID], tokens[file.ID] = string(content), secretManager.SplitSecret(string(content))
+		if len(tokens[file.ID]) == 0 {
+			log.Error(fmt.Sprintf("Secret %q: %s", file.ID, errors.ErrEmptySecret.Error()))
+			panic(errors.ErrEmptySecret)
+		}
+		secrets[file.ID] = secretManager.CreateSecret(file.ID, tokens[file.ID], robots)
+		log.Info(fmt.Sprintf("Secret %q of %d bytes split into %d tokens", file.ID, len(content), len(tokens[file.ID])))
+	}
 	// ⚠️ Buffer will receive a lot of events
 	// ⚠️ Message can be lost
 	waitGroup := sync.WaitGroup{}
 	supervisor := workers.NewSupervisor(ctx, cancel, &waitGroup, log)
 	counter := events.NewCounter()
 	once := &sync.Once{}
-
-	file, err := os.Create(config.OutputFile)
+	sink, err := output.NewSink(config)
 	if err != nil {
 		log.Error(err.Error())
 		panic(err)
 	}
-	defer file.Close()
+	parts := secretManager.NumberOfParts(secret)
+	for _, id := range secrets.IDs() {
+		domainEvent <- events.Event{
+			EventType: events.EventDistributionReport,
+			CreatedAt: time.Now().UTC(),
+			Payload: events.DistributionReportEvent{
+				SecretID:     id,
+				Distribution: string(secretManager.Distribution()),
+				Replication:  config.ReplicationFactor,
+				Parts:        secretManager.NumberOfParts(tokens[id]),
+				Report:       robot.NewDistributionReport(secrets[id]),
+			},
+		}
+	}
+	switch scheme, _ := robot.ParseScheme(config.SecretScheme); scheme {
+	case robot.ShamirScheme:
+		log.Info(fmt.Sprintf("Secret split into %d Shamir shares, %d of them reveal it", parts, config.ShamirThreshold))
+	case robot.ReedSolomonScheme:
+		log.Info(fmt.Sprintf("Secret encoded into %d Reed–Solomon fragments, any %d of them rebuild it", parts, config.ErasureDataFragments))
+	}
+	collector := snapshots.NewCollector(len(robots), parts)
+	requestSnapshot := make(chan os.Signal, 1)
+	signal.Notify(requestSnapshot, syscall.SIGUSR1) // Take a global snapshot on demand
+	defer signal.Stop(requestSnapshot)
+	go func() {
+		for range requestSnapshot {
+			collector.Request()
+		}
+	}()
 
 	// Only few workers run for each robot
+	dissemination, _ := robot.ParseDissemination(config.Dissemination)
+	reconciliation, _ := robot.ParseReconciliation(config.Reconciliation)
+	detection, _ := termination.ParseDetection(config.TerminationDetection)
+	algorithm, _ := election.ParseAlgorithm(config.Election)
+	// Election and raft messages go through a transport which can be partitioned
+	network := transport.NewPartition(transport.NewMemory(len(robots), config.BufferSize))
+	groups, _ := transport.ParseGroups(len(robots), config.Partition)
+	if len(groups) > 0 {
+		network.Split(groups...)
+		log.Info(fmt.Sprintf("Election network partitioned in %v", groups))
+	}
 	for _, r := range robots {
+		convergenceDetector := workers.NewConvergenceDetectorWorker(config, log, r, domainEvent)
+		terminationDetector := workers.NewTerminationDetectorWorker(config, log, r, robots, domainEvent)
+		if algorithm != election.Once {
+			candidacy := make(chan struct{}, 1)
+			convergenceDetector = convergenceDetector.WithCandidacy(candidacy)
+			terminationDetector = terminationDetector.WithCandidacy(candidacy)
+			if algorithm == election.Raft {
+				supervisor.Add(workers.NewRaftWorker(config, log, r, network, candidacy, domainEvent).WithName("raft worker"))
+			} else {
+				supervisor.Add(workers.NewElectionWorker(config, log, r, network, candidacy, domainEvent).WithName("election worker"))
+			}
+		}
 		supervisor.Add(
-			workers.NewProcessSummaryWorker(log, r, robots, domainEvent).WithName("summary worker"),
-			workers.NewMergeSecretWorker(log, r, domainEvent).WithName("update worker"),
-			workers.NewConvergenceDetectorWorker(config, log, r, domainEvent).WithName("convergence detector worker"),
-			workers.NewStartGossipWorker(config, log, r, robots, domainEvent).WithName("start gossip worker"),
+			workers.NewProcessSummaryWorker(config, log, r, robots, domainEvent).WithSecrets(secrets).WithName("summary worker"),
+			workers.NewMergeSecretWorker(log, r, domainEvent).WithSecrets(secrets).WithName("update worker"),
+			convergenceDetector.WithName("convergence detector worker"),
 			workers.NewQuiescenceDetectorWorker(config, log, r, domainEvent, 0).WithName("quiescence worker"),
+			workers.NewSnapshotWorker(config, log, r, robots, collector, domainEvent).WithName("snapshot worker"),
 		)
+		if dissemination.UsesAntiEntropy() {
+			supervisor.Add(workers.NewStartGossipWorker(config, log, r, robots, domainEvent).WithTopology(graph).WithRand(rand.New(rand.NewSource(rng.Int63()))).WithSecrets(secrets).WithName("start gossip worker"))
+		}
+		if detection == termination.Safra {
+			supervisor.Add(terminationDetector.WithName("termination detector worker"))
+		}
+		if reconciliation == robot.MerkleReconciliation {
+			supervisor.Add(workers.NewMerkleExchangeWorker(log, r, robots, domainEvent).WithName("merkle exchange worker"))
+		}
+		if dissemination.UsesRumors() {
+			supervisor.Add(
+				workers.NewRumorMongerWorker(config, log, r, robots, domainEvent).WithTopology(graph).WithRand(rand.New(rand.NewSource(rng.Int63()))).WithName("rumor monger worker"),
+				workers.NewRumorListenerWorker(log, r, robots, domainEvent).WithName("rumor listener worker"),
+			)
+		}
+	}
+	// The robots only detect the convergence of the other secrets, their workers handle every secret
+	for _, id := range secrets.IDs()[1:] {
+		for _, r := range secrets[id] {
+			supervisor.Add(workers.NewConvergenceDetectorWorker(config, log, r, domainEvent).WithName("convergence detector worker"))
+		}
 	}
 	// One worker is responsible for writing the secret
 	// One worker to handle the events
+	fanout := workers.NewEventFanout(log, domainEvent, telemetryEvent).Add(
+		events.NewInvariantViolationHandler(log, counter),
+		events.NewMessageDuplicatedHandler(log, counter),
+		events.NewMessageReceivedHandler(log, counter),
+		events.NewMessageReorderedHandler(log, counter),
+		events.NewMessageSentHandler(log, counter),
+		events.NewGossipRoundHandler(log, counter),
+		events.NewRumorRemovedHandler(log, counter),
+		events.NewBloomSummaryHandler(log, counter),
+		events.NewMerkleExchangeHandler(log, counter),
+		events.NewConcurrentKnowledgeHandler(log, counter),
+		events.NewGlobalTerminationHandler(log, counter),
+		events.NewGlobalSnapshotHandler(log, counter),
+		events.NewElectionHandler(log, counter),
+		events.NewRaftHandler(log, counter),
+		events.NewSecretDeliveredHandler(log, counter),
+		events.NewSecretVerificationFailedHandler(log, counter),
+		events.NewDistributionReportHandler(log, counter),
+		events.NewWorkerRestartedAfterPanicHandler(log, counter),
+		events.NewChannelCapacityHandler(log, config.LowCapacityThreshold),
+		events.NewQuiescenceDetectorHandler(log),
+		events.NewWinnerElectedHandler(config, log, robots, once, sink, domainEvent).WithContext(ctx, &waitGroup),
+	)
+	// Each secret has its own winner and output
+	for _, id := range secrets.IDs()[1:] {
+		secretConfig := config
+		secretConfig.OutputFile = output.SecretPath(config.OutputFile, string(id))
+		secretSink, err := output.NewSink(secretConfig)
+		if err != nil {
+			log.Error(err.Error())
+			panic(err)
+		}
+		fanout = fanout.Add(events.NewWinnerElectedHandler(secretConfig, log, secrets[id], &sync.Once{}, secretSink, domainEvent).WithSecret(id).WithContext(ctx, &waitGroup))
+	}
 	supervisor.Add(
-		workers.NewConvergenceObserverWorker(config, log, robots, domainEvent).WithName("convergence observer worker"),
+		workers.NewConvergenceObserverWorker(config, log, secrets.All(), domainEvent).WithName("convergence observer worker"),
 		workers.NewChannelCapacityWorker(config, log, domainEvent).WithName("channel capacity worker"),
 		workers.NewObservabilityWorker(config, log, telemetryEvent).WithName("observability worker"),
-		workers.NewEventFanout(log, domainEvent, telemetryEvent).Add(
-			events.NewInvariantViolationHandler(log, counter),
-			events.NewMessageDuplicatedHandler(log, counter),
-			events.NewMessageReceivedHandler(log, counter),
-			events.NewMessageReorderedHandler(log, counter),
-			events.NewMessageSentHandler(log, counter),
-			events.NewWorkerRestartedAfterPanicHandler(log, counter),
-			events.NewChannelCapacityHandler(log, config.LowCapacityThreshold),
-			events.NewQuiescenceDetectorHandler(log),
-			events.NewWinnerElectedHandler(config, log, robots, once, file),
-		).WithName("event fanout worker"),
+		fanout.WithName("event fanout worker"),
 	)
 	supervisor.Run()
 
@@ -85,10 +247,63 @@ func main() {
 	<-ctx.Done()
 	log.Info("Stopping supervisor...")
 	supervisor.Stop()
+
+	residue := robot.ComputeResidue(robots, parts)
+	log.Info(fmt.Sprintf("Residue: %d/%d robots can't rebuild the secret, average residue per part %.2f",
+		residue.RobotsMissing, len(robots), residue.AverageResidue))
+	for index, missing := range residue.MissingByIndex {
+		log.Debug(fmt.Sprintf("Part %d never reached %d robots that can't rebuild the secret", index, missing))
+	}
+	compareOutput(log, config.OutputFile, input, startedAt)
+	for _, id := range secrets.IDs()[1:] {
+		residue := robot.ComputeResidue(secrets[id], secretManager.NumberOfParts(tokens[id]))
+		log.Info(fmt.Sprintf("Residue of secret %q: %d/%d robots can't rebuild the secret, average residue per part %.2f",
+			id, residue.RobotsMissing, len(robots), residue.AverageResidue))
+		compareOutput(log, output.SecretPath(config.OutputFile, string(id)), inputs[id], startedAt)
+	}
+}
+
+// readSecret Reads the secret from SECRET, or from the file (or stdin) given by SECRET_FILE
+func readSecret(config conf.Config) (string, error) {
+	switch config.SecretFile {
+	case "":
+		return config.Secret, nil
+	case "-":
+		content, err := io.ReadAll(os.Stdin)
+		return string(content), err
+	default:
+		content, err := os.ReadFile(config.SecretFile)
+		return string(content), err
+	}
+}
+
+// compareOutput Checks the output file reproduces the input byte for byte.
+// A file older than the run was left by a previous one.
+func compareOutput(log *slog.Logger, path, input string, startedAt time.Time) {
+	info, err := os.Stat(path)
+	if err != nil || info.ModTime().Before(startedAt) {
+		log.Warn(fmt.Sprintf("Output %s not written by this run, nothing to compare", path))
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	expected, actual := sha256.Sum256([]byte(input)), sha256.Sum256(content)
+	if expected != actual {
+		log.Error(fmt.Sprintf("Output %s differs from the input: sha256 %x instead of %x (%d bytes instead of %d)",
+			path, actual, expected, len(content), len(input)))
+		return
+	}
+	log.Info(fmt.Sprintf("Output %s matches the input byte for byte, sha256 %x", path, actual))
 }
 
 // TODO Ajouter les validations restantes
 func validateEnvVariables(config conf.Config) error {
+	if (config.Secret == "") == (config.SecretFile == "") {
+		return errors.ErrSecretSource
+	}
 	if config.NbrOfRobots < 2 {
 		return errors.ErrNumberOfRobots
 	}
@@ -110,5 +325,111 @@ func validateEnvVariables(config conf.Config) error {
 	if config.MetricInterval <= 0 {
 		return errors.ErrNegativeMetricInterval
 	}
+	if _, _, err := workers.ParseFanout(config.GossipFanout); err != nil {
+		return err
+	}
+	if config.GossipMaxFanout < 1 {
+		return errors.ErrInvalidGossipMaxFanout
+	}
+	if _, err := workers.ParseGossipMode(config.GossipMode); err != nil {
+		return err
+	}
+	if _, err := robot.ParseSummaryVersion(config.SummaryEncoding); err != nil {
+		return err
+	}
+	if config.BloomFalsePositiveRate <= 0 || config.BloomFalsePositiveRate >= 1 {
+		return errors.ErrInvalidBloomFalsePositiveRate
+	}
+	reconciliation, err := robot.ParseReconciliation(config.Reconciliation)
+	if err != nil {
+		return err
+	}
+	if reconciliation == robot.MerkleReconciliation && !robot.IsValidMerkleLeaves(config.MerkleLeaves) {
+		return errors.ErrInvalidMerkleLeaves
+	}
+	dissemination, err := robot.ParseDissemination(config.Dissemination)
+	if err != nil {
+		return err
+	}
+	scheme, err := robot.ParseScheme(config.SecretScheme)
+	if err != nil {
+		return err
+	}
+	files, err := robot.ParseSecretFiles(config.Secrets)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		// Only the summaries and the updates tell which secret they are about.
+		// Global snapshots still run, but they only record the first secret.
+		mode, _ := workers.ParseGossipMode(config.GossipMode)
+		detection, _ := termination.ParseDetection(config.TerminationDetection)
+		algorithm, _ := election.ParseAlgorithm(config.Election)
+		if dissemination != robot.AntiEntropy || reconciliation != robot.SummaryReconciliation || mode == pb.GossipMode_PUSH ||
+			detection != termination.QuietPeriod || algorithm != election.Once {
+			return errors.ErrMultipleSecrets
+		}
+	}
+	tokenizer, err := robot.ParseTokenizer(config.Tokenizer, config.ChunkSize)
+	if err != nil {
+		return err
+	}
+	// Words are joined back by a single space, a file would never be reproduced
+	if _, words := tokenizer.(robot.WordTokenizer); words && (config.SecretFile != "" || config.Secrets != "") {
+		return errors.ErrWordsTokenizerWithFile
+	}
+	if _, err := robot.ParseDistribution(config.Distribution); err != nil {
+		return err
+	}
+	if config.ReplicationFactor < 1 || config.ReplicationFactor > config.NbrOfRobots {
+		return errors.ErrInvalidReplicationFactor
+	}
+	if config.ZipfExponent <= 1 {
+		return errors.ErrInvalidZipfExponent
+	}
+	secretManager := robot.SecretManager{Config: config}
+	shares, fragments := secretManager.Shares(), secretManager.Fragments()
+	if scheme == robot.ShamirScheme && (config.ShamirThreshold < 2 || config.ShamirThreshold > shares || shares > 255) {
+		return errors.ErrInvalidShamirParameters
+	}
+	if scheme == robot.ReedSolomonScheme && (config.ErasureDataFragments < 1 || config.ErasureDataFragments > fragments || fragments > 255) {
+		return errors.ErrInvalidErasureParameters
+	}
+	if _, err := termination.ParseDetection(config.TerminationDetection); err != nil {
+		return err
+	}
+	if config.ClockOffset < 0 || config.ClockDrift < 0 || config.ClockDrift >= 1 {
+		return errors.ErrInvalidClockSkew
+	}
+	algorithm, err := election.ParseAlgorithm(config.Election)
+	if err != nil {
+		return err
+	}
+	if algorithm == election.Raft && (config.RaftGroupSize < 1 || config.RaftGroupSize > config.NbrOfRobots || config.RaftHeartbeat <= 0) {
+		return errors.ErrInvalidRaftGroupSize
+	}
+	// The leader renews its lease every election timeout, before it expires
+	if config.ElectionTimeout <= 0 || config.ElectionLease <= config.ElectionTimeout {
+		return errors.ErrInvalidElectionTimeout
+	}
+	if _, err := transport.ParseGroups(config.NbrOfRobots, config.Partition); err != nil {
+		return err
+	}
+	if _, err := output.NewSink(config); err != nil {
+		return err
+	}
+	if config.SnapshotInterval < 0 {
+		return errors.ErrNegativeSnapshotInterval
+	}
+	// The id of the snapshot is the only value formatted into the file name
+	if verbs := strings.ReplaceAll(config.SnapshotFile, "%%", ""); strings.Count(verbs, "%") != 1 || !strings.Contains(verbs, "%d") {
+		return errors.ErrInvalidSnapshotFile
+	}
+	if dissemination.UsesRumors() {
+		if config.RumorTime <= 0 || config.RumorLossProbability < 0 || config.RumorLossProbability > 1 ||
+			(config.RumorStopAfter <= 0 && config.RumorLossProbability == 0) {
+			return errors.ErrInvalidRumorStop
+		}
+	}
 	return nil
 }
@@ -4,8 +4,26 @@ import "time"
 
 type Config struct {
 	NbrOfRobots            int           `env:"NBR_OF_ROBOTS,required=true"`
-	Secret                 string        `env:"SECRET,required=true"`
+	Secret                 string        `env:"SECRET"`
+	SecretFile             string        `env:"SECRET_FILE"`
+	Secrets                string        `env:"SECRETS"`
 	OutputFile             string        `env:"OUTPUT_FILE,required=true"`
+	OutputSinks            string        `env:"OUTPUT_SINKS,default=file"`
+	OutputWebhookURL       string        `env:"OUTPUT_WEBHOOK_URL"`
+	OutputSocket           string        `env:"OUTPUT_SOCKET"`
+	OutputRetries          int           `env:"OUTPUT_RETRIES,default=3"`
+	OutputRetryBackoff     time.Duration `env:"OUTPUT_RETRY_BACKOFF,default=100ms"`
+	OutputTimeout          time.Duration `env:"OUTPUT_TIMEOUT,default=2s"`
+	SecretScheme           string        `env:"SECRET_SCHEME,default=words"`
+	ShamirShares           int           `env:"SHAMIR_SHARES,default=0"`
+	ShamirThreshold        int           `env:"SHAMIR_THRESHOLD,default=3"`
+	ErasureFragments       int           `env:"ERASURE_FRAGMENTS,default=0"`
+	ErasureDataFragments   int           `env:"ERASURE_DATA_FRAGMENTS,default=3"`
+	Tokenizer              string        `env:"TOKENIZER,default=words"`
+	ChunkSize              int           `env:"CHUNK_SIZE,default=16"`
+	Distribution           string        `env:"DISTRIBUTION"`
+	ReplicationFactor      int           `env:"REPLICATION_FACTOR,default=1"`
+	ZipfExponent           float64       `env:"ZIPF_EXPONENT,default=1.5"`
 	BufferSize             int           `env:"BUFFER_SIZE,required=true"`
 	EndOfSecret            string        `env:"END_OF_SECRET,required=true"`
 	PercentageOfLost       int           `env:"PERCENTAGE_OF_LOST,required=true"`
@@ -15,8 +33,37 @@ type Config struct {
 	Timeout                time.Duration `env:"TIMEOUT,required=true"`
 	QuietPeriod            time.Duration `env:"QUIET_PERIOD,required=true"`
 	GossipTime             time.Duration `env:"GOSSIP_TIME,required=true"`
+	GossipFanout           string        `env:"GOSSIP_FANOUT,default=1"`
+	GossipMaxFanout        int           `env:"GOSSIP_MAX_FANOUT,default=4"`
+	GossipMode             string        `env:"GOSSIP_MODE,default=pull"`
+	SummaryEncoding        string        `env:"SUMMARY_ENCODING,default=list"`
+	BloomFalsePositiveRate float64       `env:"BLOOM_FALSE_POSITIVE_RATE,default=0.01"`
+	BloomFallbackRounds    int           `env:"BLOOM_FALLBACK_ROUNDS,default=5"`
+	Reconciliation         string        `env:"RECONCILIATION,default=summary"`
+	MerkleLeaves           int           `env:"MERKLE_LEAVES,default=64"`
+	Dissemination          string        `env:"DISSEMINATION,default=anti-entropy"`
+	RumorTime              time.Duration `env:"RUMOR_TIME,default=50ms"`
+	RumorStopAfter         int           `env:"RUMOR_STOP_AFTER,default=2"`
+	RumorLossProbability   float64       `env:"RUMOR_LOSS_PROBABILITY,default=0"`
 	MetricInterval         time.Duration `env:"METRIC_INTERVAL,required=true"`
 	ObservabilityInterval  time.Duration `env:"OBSERVABILITY_INTERVAL,required=true"`
 	LowCapacityThreshold   int           `env:"LOW_CAPACITY_THRESHOLD,required=true"`
+	Seed                   int64         `env:"SEED,default=0"`
+	ClockOffset            time.Duration `env:"CLOCK_OFFSET,default=0s"`
+	ClockDrift             float64       `env:"CLOCK_DRIFT,default=0"`
+	TerminationDetection   string        `env:"TERMINATION_DETECTION,default=quiet-period"`
+	SnapshotInterval       time.Duration `env:"SNAPSHOT_INTERVAL,default=0s"`
+	SnapshotFile           string        `env:"SNAPSHOT_FILE,default=snapshot-%d.json"`
+	Election               string        `env:"ELECTION,default=once"`
+	ElectionTimeout        time.Duration `env:"ELECTION_TIMEOUT,default=300ms"`
+	ElectionLease          time.Duration `env:"ELECTION_LEASE,default=2s"`
+	RaftGroupSize          int           `env:"RAFT_GROUP_SIZE,default=3"`
+	RaftHeartbeat          time.Duration `env:"RAFT_HEARTBEAT,default=50ms"`
+	Partition              string        `env:"PARTITION"`
+	Topology               string        `env:"TOPOLOGY,default=complete"`
+	TopologyDegree         int           `env:"TOPOLOGY_DEGREE,default=4"`
+	TopologyProbability    float64       `env:"TOPOLOGY_PROBABILITY,default=0.1"`
+	TopologyGridWidth      int           `env:"TOPOLOGY_GRID_WIDTH,default=0"`
+	TopologyAdjacency      string        `env:"TOPOLOGY_ADJACENCY"`
 	LogLevel               string        `env:"LOG_LEVEL,default=INFO"`
 }
@@ -0,0 +1,33 @@
+package clocks
+
+import "sync/atomic"
+
+// Lamport Logical clock of a robot.
+// The clock ticks on every local event (a message sent, an event emitted) and
+// jumps past the timestamp of every message received, so whenever an event
+// causally precedes another one, its timestamp is strictly lower.
+// The zero value is a clock at 0, ready to use.
+type Lamport struct {
+	time atomic.Uint64
+}
+
+// Tick Advances the clock for a local event and returns its timestamp
+func (l *Lamport) Tick() uint64 {
+	return l.time.Add(1)
+}
+
+// Witness Merges the timestamp of a received message: max(local, remote) + 1
+func (l *Lamport) Witness(remote uint64) uint64 {
+	for {
+		local := l.time.Load()
+		next := max(local, remote) + 1
+		if l.time.CompareAndSwap(local, next) {
+			return next
+		}
+	}
+}
+
+// Now Returns the current timestamp without advancing the clock
+func (l *Lamport) Now() uint64 {
+	return l.time.Load()
+}
@@ -0,0 +1,41 @@
+package clocks
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLamport_SendReceive(t *testing.T) {
+	ass := assert.New(t)
+	var sender, receiver Lamport
+
+	ass.Equal(uint64(1), sender.Tick())
+	sent := sender.Tick()
+	ass.Equal(uint64(2), sent)
+
+	// The receive event happens after the send event
+	ass.Equal(uint64(3), receiver.Witness(sent))
+	// An old message doesn't move the clock backwards
+	ass.Equal(uint64(4), receiver.Witness(1))
+	ass.Equal(uint64(4), receiver.Now())
+}
+
+func TestLamport_Concurrent(t *testing.T) {
+	ass := assert.New(t)
+	var clock Lamport
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				clock.Tick()
+				clock.Witness(uint64(j))
+			}
+		}()
+	}
+	wg.Wait()
+	ass.Equal(uint64(2000), clock.Now())
+}
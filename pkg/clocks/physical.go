@@ -0,0 +1,72 @@
+package clocks
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Physical Simulated wall clock of a robot, with an offset and a drift rate.
+// The clock starts offset from real time, then runs (1 + drift) times as fast:
+// a drift of 0.1 gains 100ms every real second, -0.1 loses as much.
+//
+// A robot only compares its own readings, so the offset cancels out of its
+// quiet-period check while the drift does not. Comparing the readings of
+// two robots, or of a robot and an observer, suffers from both.
+// A nil clock is the real clock.
+type Physical struct {
+	offset time.Duration
+	drift  float64
+	origin time.Time // Real time when the clock was created
+}
+
+func NewPhysical(offset time.Duration, drift float64) *Physical {
+	return &Physical{offset: offset, drift: drift, origin: time.Now().UTC()}
+}
+
+// RandomPhysical Draws an offset in [-maxOffset, maxOffset] and a drift in [-maxDrift, maxDrift].
+// Returns the real clock when both bounds are zero.
+func RandomPhysical(rng *rand.Rand, maxOffset time.Duration, maxDrift float64) *Physical {
+	if maxOffset == 0 && maxDrift == 0 {
+		return nil
+	}
+	offset := time.Duration((2*rng.Float64() - 1) * float64(maxOffset))
+	drift := (2*rng.Float64() - 1) * maxDrift
+	return NewPhysical(offset, drift)
+}
+
+// Now Returns the time as seen by the robot
+func (p *Physical) Now() time.Time {
+	now := time.Now().UTC()
+	if p == nil {
+		return now
+	}
+	elapsed := now.Sub(p.origin)
+	return p.origin.Add(p.offset + time.Duration(float64(elapsed)*(1+p.drift)))
+}
+
+// Period Real duration during which the clock sees d elapse
+func (p *Physical) Period(d time.Duration) time.Duration {
+	if p == nil || p.drift == 0 {
+		return d
+	}
+	return max(1, time.Duration(float64(d)/(1+p.drift)))
+}
+
+// NewTicker Returns a ticker ticking every d as seen by the robot
+func (p *Physical) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(p.Period(d))
+}
+
+func (p *Physical) Offset() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return p.offset
+}
+
+func (p *Physical) Drift() float64 {
+	if p == nil {
+		return 0
+	}
+	return p.drift
+}
@@ -0,0 +1,31 @@
+package clocks
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhysical_OffsetAndDrift(t *testing.T) {
+	ass := assert.New(t)
+	var real *Physical
+	ass.WithinDuration(time.Now().UTC(), real.Now(), 10*time.Millisecond)
+	ass.Equal(time.Second, real.Period(time.Second))
+
+	ahead := NewPhysical(time.Hour, 0)
+	ass.WithinDuration(time.Now().UTC().Add(time.Hour), ahead.Now(), 10*time.Millisecond)
+
+	fast := NewPhysical(0, 1)
+	ass.Equal(500*time.Millisecond, fast.Period(time.Second))
+	start := fast.Now()
+	time.Sleep(50 * time.Millisecond)
+	ass.GreaterOrEqual(fast.Now().Sub(start), 100*time.Millisecond, "a clock drifting by 1 runs twice as fast")
+
+	ass.Nil(RandomPhysical(rand.New(rand.NewSource(1)), 0, 0))
+	random := RandomPhysical(rand.New(rand.NewSource(1)), time.Second, 0.5)
+	ass.LessOrEqual(random.Offset().Abs(), time.Second)
+	ass.LessOrEqual(math.Abs(random.Drift()), 0.5)
+}
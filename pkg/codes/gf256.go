@@ -0,0 +1,83 @@
+package codes
+
+// Arithmetic in GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+// Addition and subtraction are both a xor, multiplication and division use
+// logarithm tables built from the generator 3.
+
+var (
+	expTable [510]byte // Doubled so that exp[log a + log b] needs no modulo
+	logTable [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		expTable[i+255] = x
+		logTable[x] = byte(i)
+		x = mulSlow(x, 3)
+	}
+}
+
+// mulSlow Carry-less multiplication reduced by the polynomial, only used to build the tables
+func mulSlow(a, b byte) byte {
+	var product byte
+	for b > 0 {
+		if b&1 == 1 {
+			product ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return product
+}
+
+func Add(a, b byte) byte {
+	return a ^ b
+}
+
+func Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// Div Divides a by b, b must not be zero
+func Div(a, b byte) byte {
+	if b == 0 {
+		panic("codes: division by zero in GF(256)")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// Exp Returns the generator raised to the power n
+func Exp(n int) byte {
+	return expTable[n%255]
+}
+
+// Interpolate Evaluates at x, byte by byte, the polynomials of lowest degree going
+// through the points (Lagrange). The points must have distinct X and Y of the same length.
+func Interpolate(points []Share, x byte) []byte {
+	values := make([]byte, len(points[0].Y))
+	for i, point := range points {
+		// Lagrange basis polynomial of the point, evaluated at x
+		basis := byte(1)
+		for j, other := range points {
+			if i != j {
+				basis = Mul(basis, Div(Add(x, other.X), Add(point.X, other.X)))
+			}
+		}
+		for b := range values {
+			values[b] = Add(values[b], Mul(point.Y[b], basis))
+		}
+	}
+	return values
+}
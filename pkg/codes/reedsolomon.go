@@ -0,0 +1,62 @@
+package codes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"robots/pkg/errors"
+)
+
+// Fragment Piece of an erasure-coded message, fragments 0 to k-1 hold the data itself
+type Fragment struct {
+	Index int
+	Data  []byte
+}
+
+// Encode Systematic Reed–Solomon code: the message, prefixed by its length, is cut into
+// k data fragments and n-k parity fragments are added. Byte by byte, the fragments are
+// the values at 1..n of the polynomial of degree below k going through the data
+// fragments, so any k fragments rebuild the message.
+func Encode(message []byte, n, k int) ([]Fragment, error) {
+	if k < 1 || k > n || n > 255 {
+		return nil, errors.ErrInvalidErasureParameters
+	}
+	framed := binary.BigEndian.AppendUint32(nil, uint32(len(message)))
+	framed = append(framed, message...)
+	size := (len(framed) + k - 1) / k
+	framed = append(framed, make([]byte, size*k-len(framed))...)
+
+	data := make([]Share, k)
+	fragments := make([]Fragment, n)
+	for i := range data {
+		data[i] = Share{X: byte(i + 1), Y: framed[i*size : (i+1)*size]}
+		fragments[i] = Fragment{Index: i, Data: data[i].Y}
+	}
+	for i := k; i < n; i++ {
+		fragments[i] = Fragment{Index: i, Data: Interpolate(data, byte(i+1))}
+	}
+	return fragments, nil
+}
+
+// Decode Rebuilds the message from any k distinct fragments, the extra ones are ignored
+func Decode(fragments []Fragment, k int) ([]byte, error) {
+	if k < 1 || len(fragments) < k {
+		return nil, fmt.Errorf("%w: %d fragments, %d needed", errors.ErrInvalidErasureParameters, len(fragments), k)
+	}
+	points := make([]Share, k)
+	seen := make(map[int]bool, k)
+	for i, fragment := range fragments[:k] {
+		if fragment.Index < 0 || fragment.Index > 254 || seen[fragment.Index] || len(fragment.Data) != len(fragments[0].Data) {
+			return nil, fmt.Errorf("%w: invalid fragment %d", errors.ErrInvalidErasureParameters, fragment.Index)
+		}
+		seen[fragment.Index] = true
+		points[i] = Share{X: byte(fragment.Index + 1), Y: fragment.Data}
+	}
+	var framed []byte
+	for i := 0; i < k; i++ {
+		framed = append(framed, Interpolate(points, byte(i+1))...)
+	}
+	if len(framed) < 4 || int(binary.BigEndian.Uint32(framed)) > len(framed)-4 {
+		return nil, fmt.Errorf("%w: corrupted length", errors.ErrInvalidErasureParameters)
+	}
+	return framed[4 : 4+binary.BigEndian.Uint32(framed)], nil
+}
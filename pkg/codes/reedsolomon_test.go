@@ -0,0 +1,29 @@
+package codes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReedSolomon_AnyKFragmentsDecode(t *testing.T) {
+	ass := assert.New(t)
+	message := []byte("Hidden beneath the old oak tree, golden coins patiently await discovery.")
+	fragments, err := Encode(message, 6, 3)
+	ass.NoError(err)
+	ass.Len(fragments, 6)
+
+	for _, subset := range [][]int{{0, 1, 2}, {3, 4, 5}, {5, 0, 3}, {1, 2, 4, 5}} {
+		var picked []Fragment
+		for _, i := range subset {
+			picked = append(picked, fragments[i])
+		}
+		decoded, err := Decode(picked, 3)
+		ass.NoError(err)
+		ass.Equal(message, decoded)
+	}
+	_, err = Decode(fragments[:2], 3)
+	ass.Error(err, "two fragments can't rebuild the message")
+	_, err = Encode(message, 2, 3)
+	ass.Error(err)
+}
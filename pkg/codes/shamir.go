@@ -0,0 +1,62 @@
+package codes
+
+import (
+	"fmt"
+	"io"
+	"robots/pkg/errors"
+)
+
+// Share Point of the polynomials hiding the secret, one polynomial per byte of the secret
+type Share struct {
+	X byte // Never 0, the secret is the value at 0
+	Y []byte
+}
+
+// Split Shamir's secret sharing: any threshold shares among n reconstruct the secret,
+// fewer shares reveal nothing about it. The coefficients are read from random.
+func Split(secret []byte, n, threshold int, random io.Reader) ([]Share, error) {
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, errors.ErrInvalidShamirParameters
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{X: byte(i + 1), Y: make([]byte, len(secret))}
+	}
+	coefficients := make([]byte, threshold)
+	for b, value := range secret {
+		coefficients[0] = value
+		if _, err := io.ReadFull(random, coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i].Y[b] = evaluate(coefficients, shares[i].X)
+		}
+	}
+	return shares, nil
+}
+
+// evaluate Horner's method
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = Add(Mul(y, x), coefficients[i])
+	}
+	return y
+}
+
+// Combine Interpolates the polynomials at 0 (Lagrange). Given fewer shares than the
+// threshold, it returns a wrong secret: the caller has to verify it.
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.ErrInvalidShamirParameters
+	}
+	size := len(shares[0].Y)
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if share.X == 0 || seen[share.X] || len(share.Y) != size {
+			return nil, fmt.Errorf("%w: invalid share %d", errors.ErrInvalidShamirParameters, share.X)
+		}
+		seen[share.X] = true
+	}
+	return Interpolate(shares, 0), nil
+}
@@ -0,0 +1,41 @@
+package codes
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGF256_MulDivRoundTrip(t *testing.T) {
+	ass := assert.New(t)
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			ass.Equal(byte(a), Div(Mul(byte(a), byte(b)), byte(b)))
+		}
+	}
+	ass.Equal(byte(0xc1), Mul(0x57, 0x83), "example of FIPS-197")
+}
+
+func TestShamir_AnyThresholdSharesReconstruct(t *testing.T) {
+	ass := assert.New(t)
+	secret := []byte("Hidden beneath the old oak tree.")
+	shares, err := Split(secret, 6, 3, rand.Reader)
+	ass.NoError(err)
+
+	for _, subset := range [][]int{{0, 1, 2}, {5, 3, 1}, {0, 2, 4, 5}} {
+		var picked []Share
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+		combined, err := Combine(picked)
+		ass.NoError(err)
+		ass.Equal(secret, combined)
+	}
+	combined, err := Combine(shares[:2])
+	ass.NoError(err)
+	ass.NotEqual(secret, combined, "below the threshold the secret is not revealed")
+
+	_, err = Split(secret, 2, 3, rand.Reader)
+	ass.Error(err)
+}
@@ -0,0 +1,98 @@
+package election
+
+import (
+	"robots/pkg/robot"
+	pb "robots/proto"
+	"time"
+)
+
+// bully Bully algorithm restricted to the robots having completed the secret.
+// A candidate challenges every robot with a higher ID and wins if none of them
+// answers within the timeout. Robots still missing parts stay silent, so the
+// highest robot having completed the secret is elected.
+//
+// Without quorum, robots that can't reach each other elect their own leader:
+// a partition leads to a split brain, detected once the partition heals
+// thanks to the leader announcing itself on every tick.
+// Once a leader is known it is kept, a higher robot completing the secret
+// later doesn't take over since the secret is already written.
+type bully struct {
+	node
+	participant bool
+	campaigning bool
+	answered    bool
+	deadline    time.Time
+}
+
+func (b *bully) Campaign(now time.Time) Step {
+	var step Step
+	b.campaign(&step, now)
+	return step
+}
+
+func (b *bully) campaign(step *Step, now time.Time) {
+	b.participant = true
+	if b.known || b.campaigning {
+		return
+	}
+	b.term++
+	b.campaigning, b.answered, b.deadline = true, false, now.Add(b.timeout)
+	step.Reports = append(step.Reports, Report{Kind: Candidacy, Term: b.term, Candidate: b.id})
+	for i := int(b.id) + 1; i < b.nbrOfRobots; i++ {
+		b.send(step, robot.ID(i), pb.ElectionKind_ELECTION, b.term)
+	}
+	// Lower candidates may have challenged the robot before it completed the secret
+	for i := 0; i < int(b.id); i++ {
+		b.send(step, robot.ID(i), pb.ElectionKind_ANSWER, b.term)
+	}
+	if int(b.id) == b.nbrOfRobots-1 {
+		b.campaigning = false
+		b.win(step, 1)
+	}
+}
+
+func (b *bully) Handle(msg *pb.ElectionMessage, now time.Time) Step {
+	var step Step
+	sender := robot.ID(msg.SenderId)
+	b.term = max(b.term, msg.Term)
+	switch msg.Kind {
+	case pb.ElectionKind_ELECTION:
+		if !b.participant || sender > b.id {
+			return step
+		}
+		b.send(&step, sender, pb.ElectionKind_ANSWER, msg.Term)
+		step.Reports = append(step.Reports, Report{Kind: Vote, Term: msg.Term, Candidate: sender, Voter: b.id})
+		if b.known && b.leader == b.id {
+			b.send(&step, sender, pb.ElectionKind_COORDINATOR, b.term)
+		}
+		b.campaign(&step, now)
+	case pb.ElectionKind_ANSWER:
+		if b.campaigning {
+			// A higher robot took over, its announcement is expected soon
+			b.answered, b.deadline = true, now.Add(2*b.timeout)
+		}
+	case pb.ElectionKind_COORDINATOR:
+		if b.learn(&step, sender, msg.Term) {
+			b.campaigning = false
+			step.Reports = append(step.Reports, Report{Kind: Vote, Term: msg.Term, Candidate: sender, Voter: b.id, Granted: true})
+		}
+	}
+	return step
+}
+
+func (b *bully) Tick(now time.Time) Step {
+	var step Step
+	switch {
+	case b.known && b.leader == b.id:
+		b.broadcast(&step, pb.ElectionKind_COORDINATOR, b.term)
+	case b.campaigning && now.After(b.deadline):
+		b.campaigning = false
+		if b.answered {
+			// The higher robot never announced itself, it may have crashed
+			b.campaign(&step, now)
+			break
+		}
+		b.win(&step, 1)
+	}
+	return step
+}
@@ -0,0 +1,141 @@
+package election
+
+import (
+	"math/rand"
+	"robots/pkg/errors"
+	"robots/pkg/robot"
+	pb "robots/proto"
+	"time"
+)
+
+// Algorithm How the robot writing the secret is chosen
+type Algorithm string
+
+const (
+	Once  Algorithm = "once"  // The first robot to converge writes, guarded by a process-local sync.Once
+	Bully Algorithm = "bully" // The highest robot having completed the secret wins, no quorum
+	Lease Algorithm = "lease" // A candidate needs the lease of a majority of the robots
+	Raft  Algorithm = "raft"  // No writer is elected, the secret is replicated by a Raft group (see package raft)
+)
+
+// ParseAlgorithm Reads the election setting (sync.Once by default)
+func ParseAlgorithm(value string) (Algorithm, error) {
+	switch Algorithm(value) {
+	case "", Once:
+		return Once, nil
+	case Bully:
+		return Bully, nil
+	case Lease:
+		return Lease, nil
+	case Raft:
+		return Raft, nil
+	default:
+		return Once, errors.ErrUnknownElection
+	}
+}
+
+type ReportKind int
+
+const (
+	Candidacy   ReportKind = iota // The robot runs for the election
+	Vote                          // The robot voted for (or against) a candidate
+	Elected                       // The robot won the election
+	SplitBrain                    // The robot learnt about a second leader
+	SteppedDown                   // The leader lost its lease and stopped leading
+)
+
+// Report Something that happened during a step of the election
+type Report struct {
+	Kind      ReportKind
+	Term      uint64
+	Candidate robot.ID // Candidate, winner, or second leader for a split brain
+	Voter     robot.ID
+	Granted   bool
+	Votes     int // Votes received by the winner
+}
+
+// Outgoing Message to send to another robot
+type Outgoing struct {
+	To      robot.ID
+	Message *pb.ElectionMessage
+}
+
+// Step Messages to send and reports produced by the elector
+type Step struct {
+	Messages []Outgoing
+	Reports  []Report
+}
+
+// Elector State machine of the election for a single robot.
+// It isn't safe for concurrent use: a single worker drives it and sends its messages.
+// The current time is given on every call so electors can be driven by a skewed clock,
+// or by a test.
+type Elector interface {
+	// Campaign Runs for the election, once the robot completed the secret
+	Campaign(now time.Time) Step
+	// Handle Processes a message received from another robot
+	Handle(msg *pb.ElectionMessage, now time.Time) Step
+	// Tick Expires the timeouts
+	Tick(now time.Time) Step
+	// Leader Returns the leader known by the robot
+	Leader() (robot.ID, bool)
+}
+
+// New Creates the elector of a robot, the rng delays retries so candidates don't collide again
+func New(algorithm Algorithm, id robot.ID, nbrOfRobots int, timeout, leaseDuration time.Duration, rng *rand.Rand) Elector {
+	base := node{id: id, nbrOfRobots: nbrOfRobots, timeout: timeout, conflicts: make(map[robot.ID]bool)}
+	switch algorithm {
+	case Lease:
+		return &lease{node: base, duration: leaseDuration, rng: rng}
+	default:
+		return &bully{node: base}
+	}
+}
+
+// node State shared by the algorithms
+type node struct {
+	id          robot.ID
+	nbrOfRobots int
+	timeout     time.Duration
+	term        uint64
+	leader      robot.ID
+	known       bool
+	conflicts   map[robot.ID]bool
+}
+
+func (n *node) Leader() (robot.ID, bool) {
+	return n.leader, n.known
+}
+
+func (n *node) send(step *Step, to robot.ID, kind pb.ElectionKind, term uint64) {
+	step.Messages = append(step.Messages, Outgoing{To: to, Message: &pb.ElectionMessage{Kind: kind, SenderId: int32(n.id), Term: term}})
+}
+
+func (n *node) broadcast(step *Step, kind pb.ElectionKind, term uint64) {
+	for i := 0; i < n.nbrOfRobots; i++ {
+		if robot.ID(i) != n.id {
+			n.send(step, robot.ID(i), kind, term)
+		}
+	}
+}
+
+// learn Records the leader announced by a robot.
+// Returns true the first time a leader is known, a different leader is a split brain.
+func (n *node) learn(step *Step, leader robot.ID, term uint64) bool {
+	if !n.known {
+		n.known, n.leader = true, leader
+		return true
+	}
+	if leader != n.leader && !n.conflicts[leader] {
+		n.conflicts[leader] = true
+		step.Reports = append(step.Reports, Report{Kind: SplitBrain, Term: term, Candidate: leader, Voter: n.id})
+	}
+	return false
+}
+
+// win Makes the robot the leader and announces it to every robot
+func (n *node) win(step *Step, votes int) {
+	n.known, n.leader = true, n.id
+	step.Reports = append(step.Reports, Report{Kind: Elected, Term: n.term, Candidate: n.id, Votes: votes})
+	n.broadcast(step, pb.ElectionKind_COORDINATOR, n.term)
+}
@@ -0,0 +1,177 @@
+package election
+
+import (
+	"math/rand"
+	"robots/pkg/robot"
+	"robots/pkg/transport"
+	pb "robots/proto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// cluster Drives the electors of every robot over a partitionable transport, on a simulated clock
+type cluster struct {
+	t         *testing.T
+	electors  []Elector
+	network   *transport.Partition
+	now       time.Time
+	reports   []Report
+	completed []bool
+}
+
+func newCluster(t *testing.T, algorithm Algorithm, nbrOfRobots int) *cluster {
+	c := &cluster{t: t, network: transport.NewPartition(transport.NewMemory(nbrOfRobots, 1000)), now: time.Unix(0, 0)}
+	for i := 0; i < nbrOfRobots; i++ {
+		c.electors = append(c.electors, New(algorithm, robot.ID(i), nbrOfRobots, 100*time.Millisecond, time.Second, rand.New(rand.NewSource(int64(i)))))
+		c.completed = append(c.completed, true)
+	}
+	return c
+}
+
+func (c *cluster) apply(from robot.ID, step Step) {
+	c.reports = append(c.reports, step.Reports...)
+	for _, outgoing := range step.Messages {
+		msg, err := proto.Marshal(outgoing.Message)
+		assert.NoError(c.t, err)
+		c.network.Send(from, outgoing.To, msg)
+	}
+}
+
+// run Lets robots campaign and exchange messages for the given simulated duration
+func (c *cluster) run(duration time.Duration) {
+	for end := c.now.Add(duration); c.now.Before(end); c.now = c.now.Add(10 * time.Millisecond) {
+		for i, elector := range c.electors {
+			if c.completed[i] {
+				c.apply(robot.ID(i), elector.Campaign(c.now))
+			}
+		}
+		for i, elector := range c.electors {
+			inbox := c.network.Inbox(robot.ID(i))
+			for len(inbox) > 0 {
+				var msg pb.ElectionMessage
+				assert.NoError(c.t, proto.Unmarshal(<-inbox, &msg))
+				c.apply(robot.ID(i), elector.Handle(&msg, c.now))
+			}
+			c.apply(robot.ID(i), elector.Tick(c.now))
+		}
+	}
+}
+
+func (c *cluster) leaders() map[robot.ID]bool {
+	leaders := make(map[robot.ID]bool)
+	for _, report := range c.reports {
+		if report.Kind == Elected {
+			leaders[report.Candidate] = true
+		}
+	}
+	return leaders
+}
+
+func (c *cluster) count(kind ReportKind) int {
+	count := 0
+	for _, report := range c.reports {
+		if report.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+func TestBully_ElectsHighestCompletedRobot(t *testing.T) {
+	ass := assert.New(t)
+	c := newCluster(t, Bully, 5)
+	c.completed[4] = false
+	c.run(time.Second)
+	ass.Equal(map[robot.ID]bool{3: true}, c.leaders())
+	for _, elector := range c.electors {
+		leader, ok := elector.Leader()
+		ass.True(ok)
+		ass.Equal(robot.ID(3), leader)
+	}
+}
+
+func TestBully_SplitBrainUnderPartition(t *testing.T) {
+	ass := assert.New(t)
+	c := newCluster(t, Bully, 6)
+	c.network.Split([]robot.ID{0, 1, 2}, []robot.ID{3, 4, 5})
+	c.run(time.Second)
+	ass.Equal(map[robot.ID]bool{2: true, 5: true}, c.leaders(), "each side elects its own leader")
+	ass.Zero(c.count(SplitBrain))
+
+	c.network.Heal()
+	c.run(time.Second)
+	ass.Positive(c.count(SplitBrain), "the split brain is detected once the partition heals")
+}
+
+func TestLease_NoSplitBrainUnderPartition(t *testing.T) {
+	ass := assert.New(t)
+	c := newCluster(t, Lease, 5)
+	c.network.Split([]robot.ID{0, 1}, []robot.ID{2, 3, 4})
+	c.run(3 * time.Second)
+	leaders := c.leaders()
+	ass.Len(leaders, 1, "only the majority elects a leader")
+	for _, minority := range []robot.ID{0, 1} {
+		ass.False(leaders[minority])
+		_, ok := c.electors[minority].Leader()
+		ass.False(ok)
+	}
+
+	c.network.Heal()
+	c.run(3 * time.Second)
+	ass.Len(c.leaders(), 1)
+	ass.Zero(c.count(SplitBrain))
+	for _, elector := range c.electors {
+		_, ok := elector.Leader()
+		ass.True(ok, "the minority learns the leader once the partition heals")
+	}
+}
+
+func TestLease_LeaderStepsDownWhenPartitionedAfterTheElection(t *testing.T) {
+	ass := assert.New(t)
+	c := newCluster(t, Lease, 5)
+	c.run(3 * time.Second)
+	first, ok := c.electors[0].Leader()
+	ass.True(ok)
+	ass.Len(c.leaders(), 1)
+
+	// The leader keeps a single robot after the election, the others form a majority
+	minority := []robot.ID{first, (first + 1) % 5}
+	var majority []robot.ID
+	for i := robot.ID(0); i < 5; i++ {
+		if i != minority[0] && i != minority[1] {
+			majority = append(majority, i)
+		}
+	}
+	c.network.Split(minority, majority)
+	c.run(3 * time.Second)
+	_, ok = c.electors[first].Leader()
+	ass.False(ok, "the leader can't renew its lease with a majority")
+	second, ok := c.electors[majority[0]].Leader()
+	ass.True(ok)
+	ass.NotEqual(first, second, "the majority elects another leader")
+
+	// The second leader is only elected once the first one stepped down
+	steppedDown, elected := -1, -1
+	for i, report := range c.reports {
+		switch {
+		case report.Kind == SteppedDown && report.Candidate == first && steppedDown < 0:
+			steppedDown = i
+		case report.Kind == Elected && report.Candidate == second:
+			elected = i
+		}
+	}
+	ass.GreaterOrEqual(steppedDown, 0)
+	ass.Less(steppedDown, elected)
+
+	c.network.Heal()
+	c.run(time.Second)
+	ass.Zero(c.count(SplitBrain))
+	for _, elector := range c.electors {
+		leader, ok := elector.Leader()
+		ass.True(ok)
+		ass.Equal(second, leader, "the former leader follows the new one")
+	}
+}
@@ -0,0 +1,173 @@
+package election
+
+import (
+	"math/rand"
+	"robots/pkg/robot"
+	pb "robots/proto"
+	"time"
+)
+
+// lease Majority-based election: every robot, even one still missing parts,
+// holds a lease it grants to a single candidate at a time, for a limited
+// duration. A candidate holding the lease of a majority of the robots wins.
+//
+// The leader renews its lease with a majority for as long as it leads: every
+// renewal round has its own term, so a late grant never extends a later round.
+// A leader that can't renew it before it expires steps down, and its voters
+// forget it once their own lease expires, which happens later since they
+// granted it after the leader asked. Two majorities always share a robot, so
+// a minority partition can't elect a leader, and a majority only elects a new
+// one once the previous leader stepped down: there are never two leaders at once.
+//
+// The lease duration is measured on the robot's own clock, a fast clock
+// releases a lease too early.
+type lease struct {
+	node
+	duration    time.Duration
+	rng         *rand.Rand
+	candidate   bool // The robot ran for the election, it runs again when the leader is lost
+	campaigning bool
+	deadline    time.Time
+	votes       map[robot.ID]bool
+	holder      robot.ID
+	expiry      time.Time
+	asked       time.Time // When the last lease request or renewal round was sent
+	renewing    bool
+	leading     time.Time // End of the lease of the leader, as granted by a majority
+}
+
+func (l *lease) quorum() int {
+	return l.nbrOfRobots/2 + 1
+}
+
+func (l *lease) Campaign(now time.Time) Step {
+	var step Step
+	if l.known || l.campaigning {
+		return step
+	}
+	l.term++
+	l.candidate, l.campaigning, l.votes, l.asked = true, true, make(map[robot.ID]bool), now
+	l.deadline = now.Add(l.timeout + time.Duration(l.rng.Int63n(int64(l.timeout)+1)))
+	step.Reports = append(step.Reports, Report{Kind: Candidacy, Term: l.term, Candidate: l.id})
+	if l.grant(&step, l.id, l.term, now) {
+		l.elect(&step, l.id)
+	}
+	l.broadcast(&step, pb.ElectionKind_LEASE_REQUEST, l.term)
+	return step
+}
+
+// grant Gives the lease to the candidate unless another one still holds it.
+// A lease isn't extended when its holder runs again, otherwise candidates
+// that all granted their own lease would keep it forever.
+func (l *lease) grant(step *Step, candidate robot.ID, term uint64, now time.Time) bool {
+	expired := !now.Before(l.expiry)
+	granted := !l.known && (l.holder == candidate || expired)
+	if granted && expired {
+		l.holder, l.expiry = candidate, now.Add(l.duration)
+	}
+	step.Reports = append(step.Reports, Report{Kind: Vote, Term: term, Candidate: candidate, Voter: l.id, Granted: granted})
+	return granted
+}
+
+// elect Counts a vote for the robot and wins with a majority.
+// While leading, the votes renew the lease instead.
+func (l *lease) elect(step *Step, voter robot.ID) {
+	l.votes[voter] = true
+	if len(l.votes) < l.quorum() {
+		return
+	}
+	switch {
+	case l.campaigning:
+		l.campaigning = false
+		l.leading = l.asked.Add(l.duration)
+		l.win(step, len(l.votes))
+	case l.renewing:
+		l.renewing = false
+		l.leading = l.asked.Add(l.duration)
+	}
+}
+
+// renew Starts a renewal round of the leader's lease, for a new term
+func (l *lease) renew(step *Step, now time.Time) {
+	l.term++
+	l.renewing, l.votes, l.asked = true, make(map[robot.ID]bool), now
+	l.holder, l.expiry = l.id, now.Add(l.duration)
+	l.elect(step, l.id)
+	l.broadcast(step, pb.ElectionKind_LEASE_RENEW, l.term)
+}
+
+// extend Renews the lease of the leader, a robot not knowing the leader yet learns it.
+// A lease still held by another candidate isn't extended, and another leader is a split brain.
+func (l *lease) extend(step *Step, leader robot.ID, term uint64, now time.Time) bool {
+	held := !l.known && l.holder != leader && now.Before(l.expiry)
+	if l.learn(step, leader, term) {
+		l.campaigning = false
+	}
+	if l.leader != leader || held {
+		return false
+	}
+	l.holder, l.expiry = leader, now.Add(l.duration)
+	return true
+}
+
+func (l *lease) Handle(msg *pb.ElectionMessage, now time.Time) Step {
+	var step Step
+	sender := robot.ID(msg.SenderId)
+	switch msg.Kind {
+	case pb.ElectionKind_LEASE_REQUEST:
+		l.term = max(l.term, msg.Term)
+		if l.grant(&step, sender, msg.Term, now) {
+			l.send(&step, sender, pb.ElectionKind_LEASE_GRANT, msg.Term)
+			break
+		}
+		l.send(&step, sender, pb.ElectionKind_LEASE_REJECT, msg.Term)
+		// Only the leader announces itself, a follower would be taken for the leader
+		if l.known && l.leader == l.id {
+			l.send(&step, sender, pb.ElectionKind_COORDINATOR, l.term)
+		}
+	case pb.ElectionKind_LEASE_RENEW:
+		l.term = max(l.term, msg.Term)
+		if l.extend(&step, sender, msg.Term, now) {
+			l.send(&step, sender, pb.ElectionKind_LEASE_GRANT, msg.Term)
+			break
+		}
+		l.send(&step, sender, pb.ElectionKind_LEASE_REJECT, msg.Term)
+	case pb.ElectionKind_LEASE_GRANT:
+		if (l.campaigning || l.renewing) && msg.Term == l.term {
+			l.elect(&step, sender)
+		}
+	case pb.ElectionKind_COORDINATOR:
+		if l.learn(&step, sender, msg.Term) {
+			l.campaigning = false
+			l.holder, l.expiry = sender, now.Add(l.duration)
+		}
+	}
+	return step
+}
+
+func (l *lease) Tick(now time.Time) Step {
+	var step Step
+	switch {
+	case l.known && l.leader == l.id && !now.Before(l.leading):
+		// The lease couldn't be renewed with a majority, another leader may be elected
+		l.known, l.renewing = false, false
+		step.Reports = append(step.Reports, Report{Kind: SteppedDown, Term: l.term, Candidate: l.id})
+	case l.known && l.leader == l.id:
+		if !l.renewing || now.After(l.asked.Add(l.timeout)) {
+			l.renew(&step, now)
+		}
+	case l.known && !now.Before(l.expiry):
+		// The leader stopped renewing its lease, it stepped down or is cut off
+		l.known = false
+		if l.candidate {
+			step = l.Campaign(now)
+		}
+	case l.campaigning && now.After(l.deadline):
+		// Votes were split or lost, run again for a new term
+		l.campaigning = false
+		step = l.Campaign(now)
+	case !l.known && !l.campaigning && l.candidate:
+		step = l.Campaign(now)
+	}
+	return step
+}
@@ -12,4 +12,37 @@ var (
 	ErrNegativeMaxAttempts            = fmt.Errorf("max attempts should be positive")
 	ErrNegativeMetricInterval         = fmt.Errorf("metric interval should be positive")
 	ErrWorkerPanic                    = fmt.Errorf("worker panic")
+	ErrInvalidGossipFanout            = fmt.Errorf("gossip fanout should be a positive number or adaptive")
+	ErrInvalidGossipMaxFanout         = fmt.Errorf("gossip max fanout should be at least 1")
+	ErrUnknownGossipMode              = fmt.Errorf("gossip mode should be pull, push or push-pull")
+	ErrUnknownSummaryEncoding         = fmt.Errorf("summary encoding should be list, ranges, bitmap, compact or bloom")
+	ErrInvalidBloomFalsePositiveRate  = fmt.Errorf("bloom false positive rate should be between 0 and 1")
+	ErrUnknownReconciliation          = fmt.Errorf("reconciliation should be summary or merkle")
+	ErrInvalidMerkleLeaves            = fmt.Errorf("merkle leaves should be a positive power of two")
+	ErrUnknownDissemination           = fmt.Errorf("dissemination should be anti-entropy, rumor or hybrid")
+	ErrInvalidRumorStop               = fmt.Errorf("rumor stop after should be positive or loss probability between 0 and 1")
+	ErrInvalidClockSkew               = fmt.Errorf("clock offset should be positive and clock drift between 0 and 1")
+	ErrUnknownTerminationDetection    = fmt.Errorf("termination detection should be quiet-period or safra")
+	ErrNegativeSnapshotInterval       = fmt.Errorf("snapshot interval should be positive")
+	ErrInvalidSnapshotFile            = fmt.Errorf("snapshot file should contain a single %%d verb for the snapshot id")
+	ErrUnknownElection                = fmt.Errorf("election should be once, bully, lease or raft")
+	ErrInvalidElectionTimeout         = fmt.Errorf("election timeout should be positive and shorter than the lease duration")
+	ErrInvalidRaftGroupSize           = fmt.Errorf("raft group size should be between 1 and the number of robots")
+	ErrInvalidPartition               = fmt.Errorf("invalid partition")
+	ErrInvalidOutputSink              = fmt.Errorf("output sinks should be file, stdout, webhook or socket")
+	ErrUnknownSecretScheme            = fmt.Errorf("secret scheme should be words, shamir or reed-solomon")
+	ErrInvalidShamirParameters        = fmt.Errorf("shamir threshold should be between 2 and the number of shares, at most 255")
+	ErrSecretSource                   = fmt.Errorf("secret should be given either by SECRET or by SECRET_FILE")
+	ErrInvalidSecrets                 = fmt.Errorf("secrets should be distinct name=path pairs separated by commas")
+	ErrMultipleSecrets                = fmt.Errorf("several secrets need anti-entropy with summaries, pull or push-pull gossip, quiet-period termination and once election")
+	ErrUnknownTokenizer               = fmt.Errorf("tokenizer should be words, runes, bytes, lines or chunks")
+	ErrInvalidChunkSize               = fmt.Errorf("chunk size should be positive")
+	ErrWordsTokenizerWithFile         = fmt.Errorf("secrets read from files should be split by runes, bytes, lines or chunks to be reproduced byte for byte")
+	ErrEmptySecret                    = fmt.Errorf("secret should contain at least one token")
+	ErrUnknownDistribution            = fmt.Errorf("distribution should be random, round-robin, chunks, zipf or single")
+	ErrInvalidReplicationFactor       = fmt.Errorf("replication factor should be between 1 and the number of robots")
+	ErrInvalidZipfExponent            = fmt.Errorf("zipf exponent should be greater than 1")
+	ErrInvalidErasureParameters       = fmt.Errorf("erasure data fragments should be between 1 and the number of fragments, at most 255")
+	ErrUnknownTopology                = fmt.Errorf("unknown topology")
+	ErrInvalidTopology                = fmt.Errorf("invalid topology parameters")
 )
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// BloomSummaryHandler handles events emitted when a robot summarizes its parts
+// with a bloom filter. It reports the configured and estimated false positive
+// rates, and how often robots had to fall back to an exact summary.
+// Useful to study the bandwidth/precision trade-off of probabilistic summaries.
+type BloomSummaryHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewBloomSummaryHandler(log *slog.Logger, counter *Counter) *BloomSummaryHandler {
+	return &BloomSummaryHandler{log: log, counter: counter}
+}
+
+func (p *BloomSummaryHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventBloomSummary:
+		payload, ok := event.Payload.(BloomSummaryEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventBloomSummary)
+		if payload.ExactFallback {
+			p.log.Debug(fmt.Sprintf("Robot %d made no progress with bloom summaries, falling back to an exact summary", payload.SenderID))
+			return
+		}
+		p.log.Debug(fmt.Sprintf("Robot %d bloom summary: %d items, %d bits, %d hashes, false positive rate %.4f (target %.4f)",
+			payload.SenderID, payload.Items, payload.Bits, payload.Hashes, payload.EstimatedFalsePositive, payload.TargetFalsePositive))
+	}
+}
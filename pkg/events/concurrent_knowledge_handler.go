@@ -0,0 +1,37 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// ConcurrentKnowledgeHandler handles events emitted when two robots hold concurrent knowledge,
+// i.e. their version vectors don't dominate each other. It counts how often
+// reconciliation really has to go both ways.
+type ConcurrentKnowledgeHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewConcurrentKnowledgeHandler(log *slog.Logger, counter *Counter) *ConcurrentKnowledgeHandler {
+	return &ConcurrentKnowledgeHandler{log: log, counter: counter}
+}
+
+func (p *ConcurrentKnowledgeHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventConcurrentKnowledge:
+		payload, ok := event.Payload.(ConcurrentKnowledgeEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventConcurrentKnowledge)
+		p.log.Debug(fmt.Sprintf("Robots %d and %d hold concurrent knowledge (vector %v)",
+			payload.ReceiverID, payload.SenderID, event.Vector))
+	}
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// DistributionReportHandler handles the report of the initial distribution of the parts.
+// Convergence time depends heavily on it, so it is logged with the run.
+type DistributionReportHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewDistributionReportHandler(log *slog.Logger, counter *Counter) *DistributionReportHandler {
+	return &DistributionReportHandler{log: log, counter: counter}
+}
+
+func (p *DistributionReportHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventDistributionReport:
+		payload, ok := event.Payload.(DistributionReportEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventDistributionReport)
+		report := payload.Report
+		p.log.Info(fmt.Sprintf("Distribution %s of %d parts of secret %q replicated %d times: %v parts per robot, min %d, max %d, mean %.2f, stddev %.2f, %d empty robots",
+			payload.Distribution, payload.Parts, payload.SecretID, payload.Replication, report.PartsPerRobot, report.Min, report.Max, report.Mean, report.StdDev, report.Empty))
+	}
+}
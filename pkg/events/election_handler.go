@@ -0,0 +1,95 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// ElectionHandler handles the events of the leader election choosing the writer of the secret.
+// Elected leaders are remembered until they step down: a second leader means
+// the election failed to guarantee a single writer, which is reported even when
+// the robots themselves never find out (the partition never heals).
+type ElectionHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+	leaders map[int]uint64
+}
+
+func NewElectionHandler(log *slog.Logger, counter *Counter) *ElectionHandler {
+	return &ElectionHandler{log: log, counter: counter, leaders: make(map[int]uint64)}
+}
+
+func (p *ElectionHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventElectionCandidacy:
+		payload, ok := event.Payload.(ElectionCandidacyEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventElectionCandidacy)
+		p.log.Debug(fmt.Sprintf("Robot %d runs for the election (term %d)", payload.ID, payload.Term))
+	case EventElectionVote:
+		payload, ok := event.Payload.(ElectionVoteEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventElectionVote)
+		p.log.Debug(fmt.Sprintf("Robot %d votes %t for robot %d (term %d)",
+			payload.VoterID, payload.Granted, payload.CandidateID, payload.Term))
+	case EventLeaderElected:
+		payload, ok := event.Payload.(LeaderElectedEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventLeaderElected)
+		p.leaders[payload.ID.ToInt()] = payload.Term
+		p.log.Info(fmt.Sprintf("Robot %d elected with %d votes (term %d)", payload.ID, payload.Votes, payload.Term))
+		if len(p.leaders) > 1 {
+			p.log.Warn(fmt.Sprintf("Split brain: %d robots were elected", len(p.leaders)))
+		}
+	case EventLeaderSteppedDown:
+		payload, ok := event.Payload.(LeaderSteppedDownEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventLeaderSteppedDown)
+		delete(p.leaders, payload.ID.ToInt())
+		p.log.Warn(fmt.Sprintf("Robot %d lost its lease and stepped down (term %d)", payload.ID, payload.Term))
+	case EventSecondWinner:
+		payload, ok := event.Payload.(SecondWinnerEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventSecondWinner)
+		p.log.Error(fmt.Sprintf("Robot %d won the secret %q already delivered by robot %d", payload.Other, payload.SecretID, payload.Winner))
+	case EventSplitBrain:
+		payload, ok := event.Payload.(SplitBrainEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventSplitBrain)
+		p.log.Warn(fmt.Sprintf("Robot %d follows robot %d but robot %d was elected too",
+			payload.ID, payload.Leader, payload.Other))
+	}
+}
@@ -26,20 +26,53 @@ const (
 	EventChannelCapacity                      EventType = "CHANNEL_CAPACITY"
 	EventAllConverged                         EventType = "ALL_CONVERGED"
 	EventWinnerElected                        EventType = "WINNER_ELECTED"
+	EventGossipRound                          EventType = "GOSSIP_ROUND"
+	EventRumorRemoved                         EventType = "RUMOR_REMOVED"
+	EventBloomSummary                         EventType = "BLOOM_SUMMARY"
+	EventMerkleExchange                       EventType = "MERKLE_EXCHANGE"
+	EventConcurrentKnowledge                  EventType = "CONCURRENT_KNOWLEDGE"
+	EventGlobalTermination                    EventType = "GLOBAL_TERMINATION"
+	EventGlobalSnapshot                       EventType = "GLOBAL_SNAPSHOT"
+	EventElectionCandidacy                    EventType = "ELECTION_CANDIDACY"
+	EventElectionVote                         EventType = "ELECTION_VOTE"
+	EventLeaderElected                        EventType = "LEADER_ELECTED"
+	EventSplitBrain                           EventType = "SPLIT_BRAIN"
+	EventLeaderSteppedDown                    EventType = "LEADER_STEPPED_DOWN"
+	EventSecondWinner                         EventType = "SECOND_WINNER"
+	EventRaftLeaderElected                    EventType = "RAFT_LEADER_ELECTED"
+	EventSecretCommitted                      EventType = "SECRET_COMMITTED"
+	EventSecretDelivered                      EventType = "SECRET_DELIVERED"
+	EventSecretVerificationFailed             EventType = "SECRET_VERIFICATION_FAILED"
+	EventDistributionReport                   EventType = "DISTRIBUTION_REPORT"
 )
 
 type Event struct {
 	EventType EventType
 	CreatedAt time.Time
 	Payload   any
+	Vector    robot.VersionVector // Version vector of the emitting robot, nil for global events
+	Lamport   uint64              // Lamport timestamp of the emitting robot, 0 for global events
 }
 
+// MessageKind Distinguishes summaries from updates when counting messages
+type MessageKind string
+
+const (
+	MessageSummary MessageKind = "summary"
+	MessageUpdate  MessageKind = "update"
+	MessageRumor   MessageKind = "rumor"
+	MessageMerkle  MessageKind = "merkle"
+)
+
 type MessageSentEvent struct {
 	SenderID robot.ID
+	Kind     MessageKind
+	Bytes    int // Size of the encoded message
 }
 
 type MessageReceivedEvent struct {
 	ReceiverID robot.ID
+	Kind       MessageKind
 }
 
 type MessageDuplicatedEvent struct{}
@@ -72,7 +105,147 @@ type AllConvergedEvent struct {
 }
 
 type WinnerElectedEvent struct {
-	ID int
+	ID       int
+	Secret   string         // Secret to write when already known (committed by Raft), built from the robot otherwise
+	SecretID robot.SecretID // Secret won, each one has its own winner and output
+	Term     uint64         // Term of the election won, fences off the winners of older terms (0 without election)
+}
+
+// SecondWinnerEvent Another robot won the same term of a single-writer election (lease, bully or raft)
+type SecondWinnerEvent struct {
+	SecretID robot.SecretID
+	Winner   robot.ID // Robot whose secret was delivered
+	Other    robot.ID
+}
+
+// GossipRoundEvent Peers chosen by a robot for a single gossip round
+type GossipRoundEvent struct {
+	SenderID robot.ID
+	Fanout   int
+	PeerIDs  []robot.ID
+}
+
+// RumorRemovedEvent A robot lost interest in spreading a secret part
+type RumorRemovedEvent struct {
+	ID    robot.ID
+	Index int
+}
+
+// BloomSummaryEvent A robot summarized its parts with a bloom filter,
+// or fell back to an exact summary after rounds without progress
+type BloomSummaryEvent struct {
+	SenderID               robot.ID
+	Items                  int
+	Bits                   int
+	Hashes                 int
+	TargetFalsePositive    float64
+	EstimatedFalsePositive float64
+	ExactFallback          bool
+}
+
+// MerkleExchangeEvent Cost of a whole Merkle reconciliation, reported by the robot ending it
+type MerkleExchangeEvent struct {
+	ExchangeID uint64
+	ID         robot.ID
+	Rounds     int
+	Bytes      int
+	Parts      int
+}
+
+// ConcurrentKnowledgeEvent Two robots each know parts the other one lacks
+type ConcurrentKnowledgeEvent struct {
+	ReceiverID robot.ID
+	SenderID   robot.ID
+}
+
+// GlobalTerminationEvent The termination detection concluded: every robot is passive
+// and no secret part is in transit
+type GlobalTerminationEvent struct {
+	InitiatorID robot.ID
+	Waves       int // Number of token round trips needed
+}
+
+// GlobalSnapshotEvent A global snapshot was assembled and checked against the secret invariants
+type GlobalSnapshotEvent struct {
+	SnapshotID uint64
+	Path       string
+	InFlight   int // Number of messages recorded in transit
+	Violations []string
+}
+
+// ElectionCandidacyEvent A robot having completed the secret runs for the election
+type ElectionCandidacyEvent struct {
+	ID   robot.ID
+	Term uint64
+}
+
+// ElectionVoteEvent A robot voted for or against a candidate
+type ElectionVoteEvent struct {
+	VoterID     robot.ID
+	CandidateID robot.ID
+	Term        uint64
+	Granted     bool
+}
+
+// LeaderElectedEvent A robot won the election and writes the secret
+type LeaderElectedEvent struct {
+	ID    robot.ID
+	Term  uint64
+	Votes int
+}
+
+// SplitBrainEvent A robot learnt about a leader other than the one it knows
+type SplitBrainEvent struct {
+	ID     robot.ID
+	Leader robot.ID // Leader known by the robot
+	Other  robot.ID
+}
+
+// LeaderSteppedDownEvent The leader couldn't renew its lease with a majority and stopped leading
+type LeaderSteppedDownEvent struct {
+	ID   robot.ID
+	Term uint64
+}
+
+// RaftLeaderElectedEvent A member of the Raft group became leader for a term
+type RaftLeaderElectedEvent struct {
+	ID    robot.ID
+	Term  uint64
+	Votes int
+}
+
+// SecretCommittedEvent A member of the Raft group applied the secret committed by a majority
+type SecretCommittedEvent struct {
+	ID         robot.ID
+	ProposerID robot.ID
+	Index      uint64
+	Term       uint64
+	Leader     bool // The member applied the secret as leader, and writes it
+}
+
+// SecretDeliveredEvent Result of the delivery of the secret to an output sink
+type SecretDeliveredEvent struct {
+	WinnerID robot.ID
+	Sink     string
+	Attempts int
+	Elapsed  time.Duration
+	Error    string // Empty when delivered
+}
+
+// SecretVerificationFailedEvent A robot holds every word but its secret doesn't match the expected hash
+type SecretVerificationFailedEvent struct {
+	ID       robot.ID
+	Expected string // SHA-256, hex encoded
+	Actual   string
+}
+
+// DistributionReportEvent How the parts of the secret were initially spread, emitted at startup
+type DistributionReportEvent struct {
+	SecretID     robot.SecretID
+	Distribution string
+	Replication  int
+	Parts        int // Distinct parts of the secret, before replication
+	Report       robot.DistributionReport
 }
 
 type LastActivity time.Time
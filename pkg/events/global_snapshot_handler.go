@@ -0,0 +1,42 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// GlobalSnapshotHandler handles the global snapshots taken while the gossip is running.
+// A snapshot violating the secret invariants is reported as an error, with every violation.
+type GlobalSnapshotHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewGlobalSnapshotHandler(log *slog.Logger, counter *Counter) *GlobalSnapshotHandler {
+	return &GlobalSnapshotHandler{log: log, counter: counter}
+}
+
+func (p *GlobalSnapshotHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventGlobalSnapshot:
+		payload, ok := event.Payload.(GlobalSnapshotEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventGlobalSnapshot)
+		if len(payload.Violations) == 0 {
+			p.log.Info(fmt.Sprintf("Global snapshot %d is consistent, %d messages in transit, written to %s",
+				payload.SnapshotID, payload.InFlight, payload.Path))
+			return
+		}
+		for _, violation := range payload.Violations {
+			p.log.Error(fmt.Sprintf("Global snapshot %d: %s", payload.SnapshotID, violation))
+		}
+	}
+}
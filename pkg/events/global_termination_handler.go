@@ -0,0 +1,37 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// GlobalTerminationHandler handles the announcement of the termination detection algorithm.
+// The announcement is logged with its Lamport timestamp, so it can be compared
+// with the winners elected by the local quiet-period heuristic.
+type GlobalTerminationHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewGlobalTerminationHandler(log *slog.Logger, counter *Counter) *GlobalTerminationHandler {
+	return &GlobalTerminationHandler{log: log, counter: counter}
+}
+
+func (p *GlobalTerminationHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventGlobalTermination:
+		payload, ok := event.Payload.(GlobalTerminationEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventGlobalTermination)
+		p.log.Info(fmt.Sprintf("Global termination detected by robot %d after %d waves (lamport %d)",
+			payload.InitiatorID, payload.Waves, event.Lamport))
+	}
+}
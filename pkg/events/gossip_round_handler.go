@@ -0,0 +1,36 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// GossipRoundHandler handles events emitted at the start of each gossip round.
+// It reports which peers a robot selected, which makes the cost of the
+// fanout visible (messages per round) next to its benefit (convergence time).
+type GossipRoundHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewGossipRoundHandler(log *slog.Logger, counter *Counter) *GossipRoundHandler {
+	return &GossipRoundHandler{log: log, counter: counter}
+}
+
+func (p *GossipRoundHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventGossipRound:
+		payload, ok := event.Payload.(GossipRoundEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventGossipRound)
+		p.log.Debug(fmt.Sprintf("Robot %d gossips with %d peers %v", payload.SenderID, payload.Fanout, payload.PeerIDs))
+	}
+}
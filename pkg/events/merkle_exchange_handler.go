@@ -0,0 +1,37 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// MerkleExchangeHandler handles events emitted at the end of a Merkle reconciliation.
+// It reports how many rounds, bytes and parts an exchange needed, so the cost
+// of Merkle trees can be compared with the naive full-index summaries.
+type MerkleExchangeHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewMerkleExchangeHandler(log *slog.Logger, counter *Counter) *MerkleExchangeHandler {
+	return &MerkleExchangeHandler{log: log, counter: counter}
+}
+
+func (p *MerkleExchangeHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventMerkleExchange:
+		payload, ok := event.Payload.(MerkleExchangeEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventMerkleExchange)
+		p.log.Debug(fmt.Sprintf("Merkle exchange %d ended by robot %d: %d rounds, %d bytes, %d parts transferred",
+			payload.ExchangeID, payload.ID, payload.Rounds, payload.Bytes, payload.Parts))
+	}
+}
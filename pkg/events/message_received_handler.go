@@ -31,6 +31,6 @@ func (p *MessageReceivedHandler) Handle(event Event) {
 		p.mu.Lock()
 		defer p.mu.Unlock()
 		p.counter.Increment(EventMessageReceived)
-		p.log.Debug(fmt.Sprintf("Robot %d received a message", payload.ReceiverID))
+		p.log.Debug(fmt.Sprintf("Robot %d received a %s message", payload.ReceiverID, payload.Kind))
 	}
 }
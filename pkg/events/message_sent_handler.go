@@ -30,6 +30,6 @@ func (p *MessageSentHandler) Handle(event Event) {
 		p.mu.Lock()
 		defer p.mu.Unlock()
 		p.counter.Increment(EventMessageSent)
-		p.log.Debug(fmt.Sprintf("Robot %d sent a message", payload.SenderID))
+		p.log.Debug(fmt.Sprintf("Robot %d sent a %s message of %d bytes", payload.SenderID, payload.Kind, payload.Bytes))
 	}
 }
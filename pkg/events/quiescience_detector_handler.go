@@ -28,6 +28,11 @@ func (p QuiescenceDetectorHandler) Handle(event Event) {
 			p.log.Error(errors.ErrInvalidPayload.Error())
 		}
 		elapsed := time.Now().Sub(payload.LastActivity.Date())
+		if elapsed < 0 {
+			// The robot's clock is ahead of ours, timing-based detection can't be trusted
+			p.log.Debug(fmt.Sprintf("Robot %d last activity is %s in the future", payload.ID, durafmt.Parse(-elapsed)))
+			return
+		}
 		p.log.Debug(fmt.Sprintf("Robot %d last activity was %s ago", payload.ID, durafmt.Parse(elapsed)))
 	}
 }
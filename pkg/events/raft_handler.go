@@ -0,0 +1,57 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// RaftHandler handles the events of the Raft group replicating the secret.
+// It checks the safety of Raft from the outside: a single leader per term,
+// and the same secret applied by every member.
+type RaftHandler struct {
+	log       *slog.Logger
+	mu        sync.Mutex
+	counter   *Counter
+	leaders   map[uint64]int
+	committed map[uint64]int
+}
+
+func NewRaftHandler(log *slog.Logger, counter *Counter) *RaftHandler {
+	return &RaftHandler{log: log, counter: counter, leaders: make(map[uint64]int), committed: make(map[uint64]int)}
+}
+
+func (p *RaftHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventRaftLeaderElected:
+		payload, ok := event.Payload.(RaftLeaderElectedEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventRaftLeaderElected)
+		p.log.Info(fmt.Sprintf("Robot %d leads the raft group with %d votes (term %d)", payload.ID, payload.Votes, payload.Term))
+		if leader, ok := p.leaders[payload.Term]; ok && leader != payload.ID.ToInt() {
+			p.log.Error(fmt.Sprintf("Robots %d and %d both lead term %d", leader, payload.ID, payload.Term))
+		}
+		p.leaders[payload.Term] = payload.ID.ToInt()
+	case EventSecretCommitted:
+		payload, ok := event.Payload.(SecretCommittedEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventSecretCommitted)
+		p.log.Info(fmt.Sprintf("Robot %d applied the secret of robot %d at index %d (term %d)",
+			payload.ID, payload.ProposerID, payload.Index, payload.Term))
+		if proposer, ok := p.committed[payload.Index]; ok && proposer != payload.ProposerID.ToInt() {
+			p.log.Error(fmt.Sprintf("Index %d holds the secrets of robots %d and %d", payload.Index, proposer, payload.ProposerID))
+		}
+		p.committed[payload.Index] = payload.ProposerID.ToInt()
+	}
+}
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// RumorRemovedHandler handles events when a robot stops spreading a rumor.
+// It is triggered each time a hot secret part becomes "removed" in the SIR model.
+// Useful to follow how fast rumors die out compared to how far they spread.
+type RumorRemovedHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewRumorRemovedHandler(log *slog.Logger, counter *Counter) *RumorRemovedHandler {
+	return &RumorRemovedHandler{log: log, counter: counter}
+}
+
+func (p *RumorRemovedHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventRumorRemoved:
+		payload, ok := event.Payload.(RumorRemovedEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventRumorRemoved)
+		p.log.Debug(fmt.Sprintf("Robot %d stopped spreading word %d, total: %d", payload.ID, payload.Index, p.counter.Get(EventRumorRemoved)))
+	}
+}
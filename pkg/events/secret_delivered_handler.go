@@ -0,0 +1,42 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+	"time"
+)
+
+// SecretDeliveredHandler handles the delivery results of the secret, one per output sink.
+// A harness can register its own handler to receive them without polling a file.
+type SecretDeliveredHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewSecretDeliveredHandler(log *slog.Logger, counter *Counter) *SecretDeliveredHandler {
+	return &SecretDeliveredHandler{log: log, counter: counter}
+}
+
+func (p *SecretDeliveredHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventSecretDelivered:
+		payload, ok := event.Payload.(SecretDeliveredEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventSecretDelivered)
+		if payload.Error != "" {
+			p.log.Error(fmt.Sprintf("Secret of robot %d not delivered to %s after %d attempts: %s",
+				payload.WinnerID, payload.Sink, payload.Attempts, payload.Error))
+			return
+		}
+		p.log.Info(fmt.Sprintf("Secret of robot %d delivered to %s in %s (%d attempts)",
+			payload.WinnerID, payload.Sink, payload.Elapsed.Round(time.Millisecond), payload.Attempts))
+	}
+}
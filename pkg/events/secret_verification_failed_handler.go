@@ -0,0 +1,36 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"robots/pkg/errors"
+	"sync"
+)
+
+// SecretVerificationFailedHandler handles robots holding every word of a secret that doesn't match its hash.
+// Such a robot never declares completion: one of its words is wrong, and parts are never replaced.
+type SecretVerificationFailedHandler struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	counter *Counter
+}
+
+func NewSecretVerificationFailedHandler(log *slog.Logger, counter *Counter) *SecretVerificationFailedHandler {
+	return &SecretVerificationFailedHandler{log: log, counter: counter}
+}
+
+func (p *SecretVerificationFailedHandler) Handle(event Event) {
+	switch event.EventType {
+	case EventSecretVerificationFailed:
+		payload, ok := event.Payload.(SecretVerificationFailedEvent)
+		if !ok {
+			p.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.counter.Increment(EventSecretVerificationFailed)
+		p.log.Error(fmt.Sprintf("Robot %d holds every word but its secret hashes to %s instead of %s, it will never complete",
+			payload.ID, payload.Actual, payload.Expected))
+	}
+}
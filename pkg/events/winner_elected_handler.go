@@ -1,55 +1,172 @@
 package events
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"robots/internal/conf"
+	"robots/pkg/election"
 	"robots/pkg/errors"
+	"robots/pkg/output"
 	"robots/pkg/robot"
 	"sync"
+	"time"
 )
 
+// WinnerElectedHandler delivers the secret of the winner to the output sinks.
+// Each sink is retried on its own, and its result is published as an event
+// on the domain events (a nil channel disables them).
+// The delivery runs in its own goroutine, so it never blocks the other events,
+// and stops with the context given by WithContext.
+// Without election, the first robot to converge wins and the others are ignored.
+// With an election, the term won is a fencing token: the winner of a later
+// term takes over the delivery (a leader stepped down and another one was
+// elected), winners of an older term are ignored, and another winner of the
+// same term is published as a second winner event instead of being hidden.
 type WinnerElectedHandler struct {
-	Config conf.Config
-	log    *slog.Logger
-	Robots []*robot.Robot
-	once   *sync.Once
-	writer io.Writer
+	Config      conf.Config
+	log         *slog.Logger
+	Robots      []*robot.Robot
+	once        *sync.Once // Only without election
+	mu          sync.Mutex
+	winner      *robot.ID          // Writer of the latest term, nil until a robot won
+	term        uint64             // Term won by the writer
+	cancel      context.CancelFunc // Stops the delivery of the writer, when another one takes over
+	delivered   bool               // The writer delivered the secret to every sink
+	sink        output.Fanout
+	domainEvent chan Event
+	Secret      robot.SecretID // Only the winners of this secret are handled
+	ctx         context.Context
+	wg          *sync.WaitGroup // Waits for the delivery
 }
 
 func NewWinnerElectedHandler(Config conf.Config, log *slog.Logger,
 	robots []*robot.Robot, once *sync.Once,
-	writer io.Writer) *WinnerElectedHandler {
-	if writer == nil {
-		panic("WinnerElectedHandler: writer must be injected")
-	}
+	sink output.Fanout, domainEvent chan Event) *WinnerElectedHandler {
 	return &WinnerElectedHandler{Config: Config, log: log, Robots: robots,
-		once: once, writer: writer,
+		once: once, sink: sink, domainEvent: domainEvent,
+		ctx: context.Background(), wg: &sync.WaitGroup{},
 	}
 }
 
+// WithContext delivers the secret under the supervisor's context, waited for by its wait group
+func (w *WinnerElectedHandler) WithContext(ctx context.Context, wg *sync.WaitGroup) *WinnerElectedHandler {
+	w.ctx, w.wg = ctx, wg
+	return w
+}
+
+// WithSecret restricts the handler to the winners of a secret, each secret having its own handler and output
+func (w *WinnerElectedHandler) WithSecret(secret robot.SecretID) *WinnerElectedHandler {
+	w.Secret = secret
+	return w
+}
+
 func (w *WinnerElectedHandler) Handle(event Event) {
 	switch event.EventType {
 	case EventWinnerElected:
 		payload, ok := event.Payload.(WinnerElectedEvent)
 		if !ok {
 			w.log.Error(errors.ErrInvalidPayload.Error())
+			return
+		}
+		if payload.SecretID != w.Secret {
+			return
 		}
-		if payload.ID <= len(w.Robots) {
+		if payload.ID < 0 || payload.ID >= len(w.Robots) {
 			w.log.Error(fmt.Sprintf("Robot %d doesn't exist", payload.ID))
 			return
 		}
-		w.writeSecret(w.Robots[payload.ID])
+		w.writeSecret(w.Robots[payload.ID], payload.Secret, payload.Term)
 	}
 }
 
-// Send the winner in the channel without blocking any other possible winner
-func (w *WinnerElectedHandler) writeSecret(r *robot.Robot) {
-	w.once.Do(func() {
-		if _, err := w.writer.Write([]byte(r.BuildSecret())); err != nil {
-			w.log.Error("failed to write secret", "err", err)
+// writeSecret Delivers the secret of the first winner without election,
+// or of the winner of the latest term with an election
+func (w *WinnerElectedHandler) writeSecret(r *robot.Robot, secret string, term uint64) {
+	if algorithm, _ := election.ParseAlgorithm(w.Config.Election); algorithm == election.Once {
+		w.once.Do(func() {
+			w.logWinner(r.ID)
+			w.start(r, secret)
+		})
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch {
+	case w.winner == nil:
+		w.logWinner(r.ID)
+	case term > w.term:
+		w.log.Info(fmt.Sprintf("Robot %d took over from robot %d at term %d", r.ID, *w.winner, term))
+		w.cancel()
+		if w.delivered {
+			w.winner, w.term = &r.ID, term
+			return
 		}
-		w.log.Info(fmt.Sprintf("Robot %d won and saved the message in file -> %s", r.ID, w.Config.OutputFile))
-	})
+	case term < w.term:
+		w.log.Debug(fmt.Sprintf("Robot %d won term %d, robot %d already won term %d", r.ID, term, *w.winner, w.term))
+		return
+	case *w.winner != r.ID:
+		w.sendEvent(EventSecondWinner, SecondWinnerEvent{SecretID: w.Secret, Winner: *w.winner, Other: r.ID})
+		return
+	default:
+		return
+	}
+	w.winner, w.term = &r.ID, term
+	w.start(r, secret)
+}
+
+func (w *WinnerElectedHandler) logWinner(id robot.ID) {
+	if w.Secret != "" {
+		w.log.Info(fmt.Sprintf("Robot %d won secret %q, delivering the message to %s", id, w.Secret, w.sink.Name()))
+		return
+	}
+	w.log.Info(fmt.Sprintf("Robot %d won, delivering the message to %s", id, w.sink.Name()))
+}
+
+// start Delivers the secret in its own goroutine, until the context is done or another writer takes over
+func (w *WinnerElectedHandler) start(r *robot.Robot, secret string) {
+	ctx, cancel := context.WithCancel(w.ctx)
+	w.cancel = cancel
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+		w.deliver(ctx, r, secret)
+	}()
+}
+
+// deliver Writes the secret to every sink, retried until the context is done
+func (w *WinnerElectedHandler) deliver(ctx context.Context, r *robot.Robot, secret string) {
+	if secret == "" {
+		secret = r.Snapshot().BuildSecret()
+	}
+	delivery := output.Delivery{Secret: secret, Manifest: output.NewManifest(w.Config, r.ID.ToInt(), secret)}
+	delivered := true
+	for _, result := range w.sink.DeliverAll(ctx, delivery) {
+		delivered = delivered && result.Err == nil
+		w.sendSecretDeliveredEvent(r.ID, result)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ctx.Err() == nil && w.winner != nil && *w.winner == r.ID {
+		w.delivered = delivered
+	}
+}
+
+// sendSecretDeliveredEvent Publishes the result of a sink
+func (w *WinnerElectedHandler) sendSecretDeliveredEvent(id robot.ID, result output.Result) {
+	payload := SecretDeliveredEvent{WinnerID: id, Sink: result.Sink, Attempts: result.Attempts, Elapsed: result.Elapsed}
+	if result.Err != nil {
+		payload.Error = result.Err.Error()
+	}
+	w.sendEvent(EventSecretDelivered, payload)
+}
+
+// sendEvent Never blocks: the handler runs on the fanout worker, which reads this channel
+func (w *WinnerElectedHandler) sendEvent(eventType EventType, payload any) {
+	select {
+	case w.domainEvent <- Event{EventType: eventType, CreatedAt: time.Now().UTC(), Payload: payload}:
+	default:
+		w.log.Debug(fmt.Sprintf("Domain event channel is full, dropping %s event", eventType))
+	}
 }
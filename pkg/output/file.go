@@ -0,0 +1,118 @@
+package output
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"robots/internal/conf"
+	"strings"
+	"time"
+)
+
+// Manifest Sidecar of the output file, for the readers to trust it
+type Manifest struct {
+	WinnerID   int       `json:"winner_id"`
+	WrittenAt  time.Time `json:"written_at"`
+	Checksum   string    `json:"checksum"` // SHA-256 of the secret, hex encoded
+	Seed       int64     `json:"seed"`
+	ConfigHash string    `json:"config_hash"` // SHA-256 of the configuration of the run, hex encoded
+}
+
+// ManifestPath Returns the path of the manifest written next to the output file
+func ManifestPath(outputFile string) string {
+	return outputFile + ".manifest.json"
+}
+
+// SecretPath Returns the output file of a named secret, next to the output file of the run:
+// secret.txt becomes secret-name.txt
+func SecretPath(outputFile, name string) string {
+	if name == "" {
+		return outputFile
+	}
+	extension := filepath.Ext(outputFile)
+	return strings.TrimSuffix(outputFile, extension) + "-" + name + extension
+}
+
+// NewManifest Describes the secret written by the winner
+func NewManifest(config conf.Config, winnerID int, secret string) Manifest {
+	return Manifest{
+		WinnerID:   winnerID,
+		WrittenAt:  time.Now().UTC(),
+		Checksum:   Checksum([]byte(secret)),
+		Seed:       config.Seed,
+		ConfigHash: ConfigHash(config),
+	}
+}
+
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ConfigHash Fingerprint of the configuration, two runs with the same hash and seed are comparable
+func ConfigHash(config conf.Config) string {
+	data, _ := json.Marshal(config)
+	return Checksum(data)
+}
+
+// FileSink Writes the secret, then its manifest, both atomically.
+// A reader finding the manifest can trust the output file it describes,
+// and the file doesn't exist until a robot won.
+type FileSink struct {
+	Path string
+}
+
+func (s FileSink) Name() string {
+	return "file"
+}
+
+func (s FileSink) Deliver(_ context.Context, delivery Delivery) error {
+	if err := WriteAtomic(s.Path, []byte(delivery.Secret)); err != nil {
+		return err
+	}
+	manifest, err := json.MarshalIndent(delivery.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteAtomic(ManifestPath(s.Path), manifest)
+}
+
+// WriteAtomic Stages the data in a temporary file of the same directory, syncs it,
+// then renames it over path. A crash leaves either the previous file or the
+// new one, never a partial file.
+func WriteAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir Makes the rename durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,36 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"robots/internal/conf"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSink_WritesFileAndManifest(t *testing.T) {
+	ass := assert.New(t)
+	config := conf.Config{OutputFile: filepath.Join(t.TempDir(), "secret.txt"), Seed: 42}
+	delivery := Delivery{Secret: "hello world.", Manifest: NewManifest(config, 3, "hello world.")}
+	ass.NoError(FileSink{Path: config.OutputFile}.Deliver(context.Background(), delivery))
+
+	content, err := os.ReadFile(config.OutputFile)
+	ass.NoError(err)
+	ass.Equal("hello world.", string(content))
+
+	data, err := os.ReadFile(ManifestPath(config.OutputFile))
+	ass.NoError(err)
+	var manifest Manifest
+	ass.NoError(json.Unmarshal(data, &manifest))
+	ass.Equal(3, manifest.WinnerID)
+	ass.Equal(int64(42), manifest.Seed)
+	ass.Equal(Checksum(content), manifest.Checksum)
+	ass.Equal(ConfigHash(config), manifest.ConfigHash)
+
+	entries, err := os.ReadDir(filepath.Dir(config.OutputFile))
+	ass.NoError(err)
+	ass.Len(entries, 2, "no temporary file is left behind")
+}
@@ -0,0 +1,241 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"robots/internal/conf"
+	errs "robots/pkg/errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Delivery What a sink receives once a robot won
+type Delivery struct {
+	Secret   string   `json:"secret"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// Sink Destination of the secret. Delivering twice the same secret must be harmless,
+// failed deliveries are retried.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, delivery Delivery) error
+}
+
+// Result Outcome of the delivery to a single sink
+type Result struct {
+	Sink     string
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+// Retry Delivery policy: the backoff doubles after each failed attempt
+type Retry struct {
+	Attempts int
+	Backoff  time.Duration
+	Timeout  time.Duration // Of a single attempt, none when zero
+}
+
+// Deliver Tries to deliver to the sink until it succeeds or the attempts are exhausted
+func (r Retry) Deliver(ctx context.Context, sink Sink, delivery Delivery) Result {
+	start := time.Now()
+	result := Result{Sink: sink.Name()}
+	backoff := r.Backoff
+	for result.Attempts < max(1, r.Attempts) {
+		result.Attempts++
+		result.Err = r.attempt(ctx, sink, delivery)
+		if result.Err == nil || result.Attempts >= r.Attempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			result.Err = errors.Join(result.Err, ctx.Err())
+			result.Elapsed = time.Since(start)
+			return result
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result
+}
+
+func (r Retry) attempt(ctx context.Context, sink Sink, delivery Delivery) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	return sink.Deliver(ctx, delivery)
+}
+
+// Fanout Delivers to several sinks at once, each one with its own retries
+type Fanout struct {
+	Sinks []Sink
+	Retry Retry
+}
+
+func (f Fanout) Name() string {
+	names := make([]string, len(f.Sinks))
+	for i, sink := range f.Sinks {
+		names[i] = sink.Name()
+	}
+	return "fanout(" + strings.Join(names, ",") + ")"
+}
+
+// DeliverAll Returns the result of every sink, in the order of the sinks
+func (f Fanout) DeliverAll(ctx context.Context, delivery Delivery) []Result {
+	results := make([]Result, len(f.Sinks))
+	var wg sync.WaitGroup
+	for i, sink := range f.Sinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = f.Retry.Deliver(ctx, sink, delivery)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (f Fanout) Deliver(ctx context.Context, delivery Delivery) error {
+	var err error
+	for _, result := range f.DeliverAll(ctx, delivery) {
+		if result.Err != nil {
+			err = errors.Join(err, fmt.Errorf("%s: %w", result.Sink, result.Err))
+		}
+	}
+	return err
+}
+
+// WriterSink Writes the secret as is, to the standard output by default.
+// Nothing is appended, binary and file secrets keep their exact bytes.
+type WriterSink struct {
+	Writer io.Writer
+}
+
+func (s WriterSink) Name() string {
+	return "stdout"
+}
+
+func (s WriterSink) Deliver(_ context.Context, delivery Delivery) error {
+	writer := s.Writer
+	if writer == nil {
+		writer = os.Stdout
+	}
+	_, err := io.WriteString(writer, delivery.Secret)
+	return err
+}
+
+// WebhookSink Posts the delivery as JSON, any status other than 2xx is a failure
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s WebhookSink) Deliver(ctx context.Context, delivery Delivery) error {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return nil
+}
+
+// SocketSink Sends the delivery as a line of JSON over a Unix domain socket
+type SocketSink struct {
+	Path string
+}
+
+func (s SocketSink) Name() string {
+	return "socket"
+}
+
+func (s SocketSink) Deliver(ctx context.Context, delivery Delivery) error {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", s.Path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	_, err = conn.Write(append(body, '\n'))
+	return err
+}
+
+// NewSink Builds the sinks listed in the configuration, such as "file,webhook".
+// An empty setting is the file sink, but every listed name must be a sink, and only once:
+// a sink listed twice would deliver twice.
+func NewSink(config conf.Config) (Fanout, error) {
+	fanout := Fanout{Retry: Retry{Attempts: config.OutputRetries, Backoff: config.OutputRetryBackoff, Timeout: config.OutputTimeout}}
+	names := config.OutputSinks
+	if strings.TrimSpace(names) == "" {
+		names = "file"
+	}
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			return fanout, fmt.Errorf("%w: %q is listed twice", errs.ErrInvalidOutputSink, name)
+		}
+		seen[name] = true
+		var sink Sink
+		switch name {
+		case "file":
+			sink = FileSink{Path: config.OutputFile}
+		case "stdout":
+			sink = WriterSink{}
+		case "webhook":
+			if config.OutputWebhookURL == "" {
+				return fanout, fmt.Errorf("%w: webhook requires OUTPUT_WEBHOOK_URL", errs.ErrInvalidOutputSink)
+			}
+			sink = WebhookSink{URL: config.OutputWebhookURL}
+		case "socket":
+			if config.OutputSocket == "" {
+				return fanout, fmt.Errorf("%w: socket requires OUTPUT_SOCKET", errs.ErrInvalidOutputSink)
+			}
+			sink = SocketSink{Path: config.OutputSocket}
+		default:
+			return fanout, fmt.Errorf("%w: %q", errs.ErrInvalidOutputSink, name)
+		}
+		fanout.Sinks = append(fanout.Sinks, sink)
+	}
+	return fanout, nil
+}
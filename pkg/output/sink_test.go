@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"robots/internal/conf"
+	errs "robots/pkg/errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakySink Fails the first attempts
+type flakySink struct {
+	failures int32
+	calls    atomic.Int32
+}
+
+func (s *flakySink) Name() string {
+	return "flaky"
+}
+
+func (s *flakySink) Deliver(context.Context, Delivery) error {
+	if s.calls.Add(1) <= s.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestFanout_DeliversToEverySinkWithRetries(t *testing.T) {
+	ass := assert.New(t)
+	delivery := Delivery{Secret: "hello world.", Manifest: Manifest{WinnerID: 2}}
+
+	var posted Delivery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ass.NoError(json.NewDecoder(r.Body).Decode(&posted))
+	}))
+	defer server.Close()
+
+	socket := filepath.Join(t.TempDir(), "secret.sock")
+	listener, err := net.Listen("unix", socket)
+	ass.NoError(err)
+	defer listener.Close()
+	received := make(chan Delivery, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var delivery Delivery
+		line, _ := bufio.NewReader(conn).ReadBytes('\n')
+		_ = json.Unmarshal(line, &delivery)
+		received <- delivery
+	}()
+
+	flaky := &flakySink{failures: 2}
+	down := &flakySink{failures: 10}
+	fanout := Fanout{
+		Sinks: []Sink{WebhookSink{URL: server.URL}, SocketSink{Path: socket}, flaky, down},
+		Retry: Retry{Attempts: 3, Backoff: time.Millisecond, Timeout: time.Second},
+	}
+	results := fanout.DeliverAll(context.Background(), delivery)
+
+	ass.NoError(results[0].Err)
+	ass.Equal(delivery, posted)
+	ass.NoError(results[1].Err)
+	ass.Equal(delivery, <-received)
+	ass.NoError(results[2].Err)
+	ass.Equal(3, results[2].Attempts)
+	ass.Error(results[3].Err)
+	ass.Equal(3, results[3].Attempts)
+	ass.Error(fanout.Deliver(context.Background(), delivery), "the fanout fails when a sink fails")
+}
+
+func TestNewSink_EveryListedSinkOnce(t *testing.T) {
+	ass := assert.New(t)
+	fanout, err := NewSink(conf.Config{OutputFile: "secret.txt"})
+	ass.NoError(err)
+	ass.Equal("fanout(file)", fanout.Name(), "the file sink by default")
+	fanout, err = NewSink(conf.Config{OutputSinks: " stdout , file", OutputFile: "secret.txt"})
+	ass.NoError(err)
+	ass.Equal("fanout(stdout,file)", fanout.Name())
+	for _, sinks := range []string{"stdout,", "file,,stdout", "stdout,file,stdout", "file, file"} {
+		_, err := NewSink(conf.Config{OutputSinks: sinks, OutputFile: "secret.txt"})
+		ass.ErrorIs(err, errs.ErrInvalidOutputSink, sinks)
+	}
+
+	var written bytes.Buffer
+	secret := "key = \"value\"\n\xff\x00"
+	ass.NoError(WriterSink{Writer: &written}.Deliver(context.Background(), Delivery{Secret: secret}))
+	ass.Equal(secret, written.String(), "the secret is written as is")
+}
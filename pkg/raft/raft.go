@@ -0,0 +1,343 @@
+package raft
+
+import (
+	"math/rand"
+	"robots/pkg/robot"
+	pb "robots/proto"
+	"slices"
+	"time"
+)
+
+type Role int
+
+const (
+	Follower Role = iota
+	Candidate
+	Leader
+)
+
+type ReportKind int
+
+const (
+	Candidacy ReportKind = iota // The node runs for leader
+	Vote                        // The node voted for a candidate
+	Elected                     // The node became leader
+	Committed                   // An entry holding a secret was applied
+)
+
+// Report Something that happened during a step of the node
+type Report struct {
+	Kind      ReportKind
+	Term      uint64
+	Candidate robot.ID
+	Granted   bool
+	Votes     int
+	Index     uint64
+	Entry     *pb.RaftEntry
+	Leader    bool // The node applied the entry as leader
+}
+
+// Outgoing Message to send to another robot
+type Outgoing struct {
+	To      robot.ID
+	Message *pb.RaftMessage
+}
+
+// Step Messages to send and reports produced by the node
+type Step struct {
+	Messages []Outgoing
+	Reports  []Report
+}
+
+// Node Raft member replicating the secret proposed by the robots.
+// The replicated state machine only keeps the first secret of the log: later
+// proposals are ignored by the leader once a secret was appended.
+// A leader appends an empty entry when elected so that it can commit the
+// entries of previous terms.
+//
+// A Node isn't safe for concurrent use, a single worker drives it. The state
+// survives a crash of the worker, as if it was persisted. The current time is
+// given on every call so nodes can be driven by a skewed clock or by a test.
+type Node struct {
+	id              robot.ID
+	members         []robot.ID
+	electionTimeout time.Duration
+	heartbeat       time.Duration
+	rng             *rand.Rand
+	role            Role
+	term            uint64
+	votedFor        robot.ID
+	voted           bool
+	log             []*pb.RaftEntry
+	commitIndex     uint64
+	lastApplied     uint64
+	leader          robot.ID
+	leaderKnown     bool
+	deadline        time.Time
+	nextHeartbeat   time.Time
+	votes           map[robot.ID]bool
+	nextIndex       map[robot.ID]uint64
+	matchIndex      map[robot.ID]uint64
+	armed           bool
+}
+
+// New Creates a member of the group, the rng randomizes the election timeouts
+func New(id robot.ID, members []robot.ID, electionTimeout, heartbeat time.Duration, rng *rand.Rand) *Node {
+	return &Node{id: id, members: members, electionTimeout: electionTimeout, heartbeat: heartbeat, rng: rng}
+}
+
+// Members Robots of a group of the given size, the lowest IDs
+func Members(size int) []robot.ID {
+	members := make([]robot.ID, size)
+	for i := range members {
+		members[i] = robot.ID(i)
+	}
+	return members
+}
+
+func (n *Node) Role() Role {
+	return n.role
+}
+
+func (n *Node) Term() uint64 {
+	return n.term
+}
+
+// Leader Returns the leader of the current term, if known
+func (n *Node) Leader() (robot.ID, bool) {
+	return n.leader, n.leaderKnown
+}
+
+// CommitIndex Returns the index of the last entry committed by a majority
+func (n *Node) CommitIndex() uint64 {
+	return n.commitIndex
+}
+
+// Secret Returns the committed secret entry, if any
+func (n *Node) Secret() (*pb.RaftEntry, bool) {
+	for _, entry := range n.log[:n.commitIndex] {
+		if entry.ProposerId >= 0 {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+func (n *Node) quorum() int {
+	return len(n.members)/2 + 1
+}
+
+func (n *Node) lastLog() (uint64, uint64) {
+	if len(n.log) == 0 {
+		return 0, 0
+	}
+	return uint64(len(n.log)), n.log[len(n.log)-1].Term
+}
+
+func (n *Node) termAt(index uint64) uint64 {
+	if index == 0 || index > uint64(len(n.log)) {
+		return 0
+	}
+	return n.log[index-1].Term
+}
+
+func (n *Node) resetDeadline(now time.Time) {
+	n.deadline = now.Add(n.electionTimeout + time.Duration(n.rng.Int63n(int64(n.electionTimeout)+1)))
+	n.armed = true
+}
+
+func (n *Node) send(step *Step, to robot.ID, msg *pb.RaftMessage) {
+	msg.SenderId, msg.Term = int32(n.id), n.term
+	step.Messages = append(step.Messages, Outgoing{To: to, Message: msg})
+}
+
+// Tick Starts an election when the leader is silent, and sends the heartbeats of a leader
+func (n *Node) Tick(now time.Time) Step {
+	var step Step
+	if !n.armed {
+		n.resetDeadline(now)
+	}
+	switch {
+	case n.role == Leader && !now.Before(n.nextHeartbeat):
+		n.replicate(&step, now)
+	case n.role != Leader && now.After(n.deadline):
+		n.campaign(&step, now)
+	}
+	return step
+}
+
+func (n *Node) campaign(step *Step, now time.Time) {
+	n.term++
+	n.role, n.votedFor, n.voted, n.leaderKnown = Candidate, n.id, true, false
+	n.votes = map[robot.ID]bool{n.id: true}
+	n.resetDeadline(now)
+	step.Reports = append(step.Reports, Report{Kind: Candidacy, Term: n.term, Candidate: n.id})
+	lastIndex, lastTerm := n.lastLog()
+	for _, member := range n.members {
+		if member != n.id {
+			n.send(step, member, &pb.RaftMessage{Kind: pb.RaftKind_REQUEST_VOTE, LastLogIndex: lastIndex, LastLogTerm: lastTerm})
+		}
+	}
+	n.countVotes(step, now)
+}
+
+func (n *Node) countVotes(step *Step, now time.Time) {
+	if n.role != Candidate || len(n.votes) < n.quorum() {
+		return
+	}
+	n.role, n.leader, n.leaderKnown = Leader, n.id, true
+	step.Reports = append(step.Reports, Report{Kind: Elected, Term: n.term, Candidate: n.id, Votes: len(n.votes)})
+	n.log = append(n.log, &pb.RaftEntry{Term: n.term, ProposerId: -1})
+	n.nextIndex, n.matchIndex = make(map[robot.ID]uint64), make(map[robot.ID]uint64)
+	for _, member := range n.members {
+		n.nextIndex[member] = uint64(len(n.log))
+	}
+	n.matchIndex[n.id] = uint64(len(n.log))
+	n.advanceCommit(step)
+	n.replicate(step, now)
+}
+
+// replicate Sends the entries each follower is missing, or an empty heartbeat
+func (n *Node) replicate(step *Step, now time.Time) {
+	n.nextHeartbeat = now.Add(n.heartbeat)
+	for _, member := range n.members {
+		if member == n.id {
+			continue
+		}
+		prev := n.nextIndex[member] - 1
+		n.send(step, member, &pb.RaftMessage{
+			Kind:         pb.RaftKind_APPEND_ENTRIES,
+			PrevLogIndex: prev,
+			PrevLogTerm:  n.termAt(prev),
+			Entries:      n.log[prev:],
+			LeaderCommit: n.commitIndex,
+		})
+	}
+}
+
+// Handle Processes a message received from another robot
+func (n *Node) Handle(msg *pb.RaftMessage, now time.Time) Step {
+	var step Step
+	sender := robot.ID(msg.SenderId)
+	if msg.Kind == pb.RaftKind_PROPOSE {
+		n.propose(&step, sender, msg, now)
+		return step
+	}
+	if msg.Term > n.term {
+		n.term, n.role, n.voted, n.leaderKnown = msg.Term, Follower, false, false
+	}
+	switch msg.Kind {
+	case pb.RaftKind_REQUEST_VOTE:
+		lastIndex, lastTerm := n.lastLog()
+		upToDate := msg.LastLogTerm > lastTerm || (msg.LastLogTerm == lastTerm && msg.LastLogIndex >= lastIndex)
+		granted := msg.Term == n.term && (!n.voted || n.votedFor == sender) && upToDate
+		if granted {
+			n.votedFor, n.voted = sender, true
+			n.resetDeadline(now)
+		}
+		step.Reports = append(step.Reports, Report{Kind: Vote, Term: n.term, Candidate: sender, Granted: granted})
+		n.send(&step, sender, &pb.RaftMessage{Kind: pb.RaftKind_VOTE, Granted: granted})
+	case pb.RaftKind_VOTE:
+		if n.role == Candidate && msg.Term == n.term && msg.Granted {
+			n.votes[sender] = true
+			n.countVotes(&step, now)
+		}
+	case pb.RaftKind_APPEND_ENTRIES:
+		n.append(&step, sender, msg, now)
+	case pb.RaftKind_APPEND_RESULT:
+		if n.role != Leader || msg.Term != n.term {
+			break
+		}
+		if msg.Granted {
+			n.matchIndex[sender] = max(n.matchIndex[sender], msg.MatchIndex)
+			n.nextIndex[sender] = n.matchIndex[sender] + 1
+			n.advanceCommit(&step)
+			break
+		}
+		n.nextIndex[sender] = max(1, min(n.nextIndex[sender]-1, msg.MatchIndex+1))
+	}
+	return step
+}
+
+func (n *Node) append(step *Step, sender robot.ID, msg *pb.RaftMessage, now time.Time) {
+	reply := &pb.RaftMessage{Kind: pb.RaftKind_APPEND_RESULT}
+	if msg.Term < n.term {
+		n.send(step, sender, reply)
+		return
+	}
+	n.role, n.leader, n.leaderKnown = Follower, sender, true
+	n.resetDeadline(now)
+	if msg.PrevLogIndex > uint64(len(n.log)) || n.termAt(msg.PrevLogIndex) != msg.PrevLogTerm {
+		reply.MatchIndex = min(uint64(len(n.log)), msg.PrevLogIndex-1)
+		n.send(step, sender, reply)
+		return
+	}
+	for i, entry := range msg.Entries {
+		index := msg.PrevLogIndex + uint64(i) + 1
+		if index <= uint64(len(n.log)) {
+			if n.log[index-1].Term == entry.Term {
+				continue
+			}
+			// Conflicting entries were never committed, they are replaced by the leader's
+			n.log = n.log[:index-1]
+		}
+		n.log = append(n.log, entry)
+	}
+	last := msg.PrevLogIndex + uint64(len(msg.Entries))
+	if msg.LeaderCommit > n.commitIndex {
+		n.commitIndex = min(msg.LeaderCommit, last)
+		n.apply(step)
+	}
+	reply.Granted, reply.MatchIndex = true, last
+	n.send(step, sender, reply)
+}
+
+// advanceCommit Commits the entries of the current term stored on a majority
+func (n *Node) advanceCommit(step *Step) {
+	for index := uint64(len(n.log)); index > n.commitIndex; index-- {
+		if n.log[index-1].Term != n.term {
+			break
+		}
+		matches := 0
+		for _, member := range n.members {
+			if n.matchIndex[member] >= index {
+				matches++
+			}
+		}
+		if matches >= n.quorum() {
+			n.commitIndex = index
+			n.apply(step)
+			return
+		}
+	}
+}
+
+// apply Reports the committed secret entries, the empty ones are skipped
+func (n *Node) apply(step *Step) {
+	for ; n.lastApplied < n.commitIndex; n.lastApplied++ {
+		entry := n.log[n.lastApplied]
+		if entry.ProposerId < 0 {
+			continue
+		}
+		step.Reports = append(step.Reports, Report{Kind: Committed, Term: entry.Term, Index: n.lastApplied + 1, Entry: entry, Leader: n.role == Leader})
+	}
+}
+
+// propose Appends the first secret proposed to the leader, the proposer is told once it is committed.
+// Proposals received by followers are ignored, the proposer sends them to every member.
+func (n *Node) propose(step *Step, sender robot.ID, msg *pb.RaftMessage, now time.Time) {
+	if committed, ok := n.Secret(); ok {
+		n.send(step, sender, &pb.RaftMessage{Kind: pb.RaftKind_COMMITTED, Entries: []*pb.RaftEntry{committed}})
+		return
+	}
+	if n.role != Leader || len(msg.Entries) == 0 {
+		return
+	}
+	if slices.ContainsFunc(n.log, func(entry *pb.RaftEntry) bool { return entry.ProposerId >= 0 }) {
+		return
+	}
+	n.log = append(n.log, &pb.RaftEntry{Term: n.term, ProposerId: msg.Entries[0].ProposerId, Secret: msg.Entries[0].Secret})
+	n.matchIndex[n.id] = uint64(len(n.log))
+	n.advanceCommit(step)
+	n.replicate(step, now)
+}
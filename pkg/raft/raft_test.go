@@ -0,0 +1,172 @@
+package raft
+
+import (
+	"math/rand"
+	"robots/pkg/robot"
+	"robots/pkg/transport"
+	pb "robots/proto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// group Drives the nodes over a partitionable transport, on a simulated clock.
+// A crashed node neither ticks nor receives messages, its state is kept.
+type group struct {
+	t       *testing.T
+	nodes   []*Node
+	network *transport.Partition
+	crashed map[robot.ID]bool
+	now     time.Time
+	reports map[robot.ID][]Report
+}
+
+func newGroup(t *testing.T, size int) *group {
+	g := &group{t: t, network: transport.NewPartition(transport.NewMemory(size, 1000)), crashed: make(map[robot.ID]bool),
+		now: time.Unix(0, 0), reports: make(map[robot.ID][]Report)}
+	for i := 0; i < size; i++ {
+		g.nodes = append(g.nodes, New(robot.ID(i), Members(size), 100*time.Millisecond, 20*time.Millisecond, rand.New(rand.NewSource(int64(i)))))
+	}
+	return g
+}
+
+func (g *group) apply(from robot.ID, step Step) {
+	g.reports[from] = append(g.reports[from], step.Reports...)
+	for _, outgoing := range step.Messages {
+		msg, err := proto.Marshal(outgoing.Message)
+		assert.NoError(g.t, err)
+		g.network.Send(from, outgoing.To, msg)
+	}
+}
+
+func (g *group) propose(to robot.ID, proposer robot.ID, secret string) {
+	msg, err := proto.Marshal(&pb.RaftMessage{Kind: pb.RaftKind_PROPOSE, SenderId: int32(proposer),
+		Entries: []*pb.RaftEntry{{ProposerId: int32(proposer), Secret: []byte(secret)}}})
+	assert.NoError(g.t, err)
+	g.network.Send(proposer, to, msg)
+}
+
+func (g *group) run(duration time.Duration) {
+	for end := g.now.Add(duration); g.now.Before(end); g.now = g.now.Add(5 * time.Millisecond) {
+		for i, node := range g.nodes {
+			id := robot.ID(i)
+			inbox := g.network.Inbox(id)
+			for len(inbox) > 0 {
+				data := <-inbox
+				if g.crashed[id] {
+					continue
+				}
+				var msg pb.RaftMessage
+				assert.NoError(g.t, proto.Unmarshal(data, &msg))
+				g.apply(id, node.Handle(&msg, g.now))
+			}
+			if !g.crashed[id] {
+				g.apply(id, node.Tick(g.now))
+			}
+		}
+	}
+}
+
+func (g *group) leader() (robot.ID, bool) {
+	for i, node := range g.nodes {
+		if node.Role() == Leader && !g.crashed[robot.ID(i)] {
+			return robot.ID(i), true
+		}
+	}
+	return 0, false
+}
+
+func (g *group) committed(id robot.ID) []Report {
+	var committed []Report
+	for _, report := range g.reports[id] {
+		if report.Kind == Committed {
+			committed = append(committed, report)
+		}
+	}
+	return committed
+}
+
+func TestRaft_CommitsFirstProposalOnEveryMember(t *testing.T) {
+	ass := assert.New(t)
+	g := newGroup(t, 3)
+	g.run(time.Second)
+	leader, ok := g.leader()
+	ass.True(ok)
+	for i := range g.nodes {
+		g.propose(robot.ID(i), 1, "hello world.")
+		g.propose(robot.ID(i), 2, "another secret.")
+	}
+	g.run(time.Second)
+	for i, node := range g.nodes {
+		entry, ok := node.Secret()
+		ass.True(ok)
+		ass.Equal("hello world.", string(entry.Secret))
+		committed := g.committed(robot.ID(i))
+		ass.Len(committed, 1, "only the first secret is applied")
+		ass.Equal(robot.ID(i) == leader, committed[0].Leader)
+	}
+}
+
+func TestRaft_MinorityLeaderCannotCommit(t *testing.T) {
+	ass := assert.New(t)
+	g := newGroup(t, 5)
+	g.run(time.Second)
+	oldLeader, ok := g.leader()
+	ass.True(ok)
+	var majority []robot.ID
+	for i := range g.nodes {
+		if robot.ID(i) != oldLeader {
+			majority = append(majority, robot.ID(i))
+		}
+	}
+	g.network.Split([]robot.ID{oldLeader, majority[0]}, majority[1:])
+
+	// The isolated leader appends the proposal but can't commit it
+	g.propose(oldLeader, oldLeader, "minority secret.")
+	g.run(time.Second)
+	_, ok = g.nodes[oldLeader].Secret()
+	ass.False(ok)
+
+	for _, id := range majority[1:] {
+		g.propose(id, id, "majority secret.")
+	}
+	g.run(time.Second)
+	entry, ok := g.nodes[majority[1]].Secret()
+	ass.True(ok)
+	ass.Equal("majority secret.", string(entry.Secret))
+
+	// Once healed, the old leader steps down and its uncommitted entry is replaced
+	g.network.Heal()
+	g.run(time.Second)
+	for _, node := range g.nodes {
+		entry, ok := node.Secret()
+		ass.True(ok)
+		ass.Equal("majority secret.", string(entry.Secret))
+	}
+}
+
+func TestRaft_SurvivesLeaderCrash(t *testing.T) {
+	ass := assert.New(t)
+	g := newGroup(t, 3)
+	g.run(time.Second)
+	leader, _ := g.leader()
+	g.crashed[leader] = true
+	g.run(time.Second)
+	newLeader, ok := g.leader()
+	ass.True(ok)
+	ass.NotEqual(leader, newLeader)
+
+	for i := range g.nodes {
+		g.propose(robot.ID(i), 0, "hello world.")
+	}
+	g.run(time.Second)
+	delete(g.crashed, leader)
+	g.run(time.Second)
+	for _, node := range g.nodes {
+		entry, ok := node.Secret()
+		ass.True(ok, "the restarted node catches up")
+		ass.Equal("hello world.", string(entry.Secret))
+	}
+}
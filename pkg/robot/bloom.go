@@ -0,0 +1,105 @@
+package robot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// BloomFilter Probabilistic set of secret parts.
+// A part is identified by its (index, hash(word)) pair, so a robot holding a
+// different word for the same index is not mistaken for holding the part.
+// There are no false negatives, but a part may wrongly look present
+// (false positive), in which case the responder won't send it.
+type BloomFilter struct {
+	Bits   []byte
+	Hashes uint32
+	Items  uint32
+}
+
+// NewBloomFilter Sizes a filter for the expected number of items and false positive rate:
+// m = -n.ln(p) / ln(2)² bits and k = m/n.ln(2) hash functions
+func NewBloomFilter(items int, falsePositiveRate float64) *BloomFilter {
+	n := math.Max(1, float64(items))
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/n*math.Ln2))
+	return &BloomFilter{Bits: make([]byte, int(math.Ceil(m/8))), Hashes: uint32(k)}
+}
+
+func (b *BloomFilter) Add(part SecretPart) {
+	for _, position := range b.positions(part) {
+		b.Bits[position/8] |= 1 << (position % 8)
+	}
+	b.Items++
+}
+
+func (b *BloomFilter) MayContain(part SecretPart) bool {
+	if len(b.Bits) == 0 {
+		return false
+	}
+	for _, position := range b.positions(part) {
+		if b.Bits[position/8]&(1<<(position%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Holds Parts that may be in the filter are considered held by the receiver
+func (b *BloomFilter) Holds(part SecretPart) bool {
+	return b.MayContain(part)
+}
+
+// FalsePositiveRate Estimated rate for the current fill: (1 - e^(-kn/m))^k
+func (b *BloomFilter) FalsePositiveRate() float64 {
+	m := float64(len(b.Bits) * 8)
+	if m == 0 {
+		return 1
+	}
+	k := float64(b.Hashes)
+	return math.Pow(1-math.Exp(-k*float64(b.Items)/m), k)
+}
+
+// positions Double hashing: the i-th position is h1 + i.h2 modulo the number of bits
+func (b *BloomFilter) positions(part SecretPart) []uint64 {
+	word := sha256.Sum256(append([]byte(part.Word), part.Data...))
+	key := make([]byte, 8+len(word))
+	binary.BigEndian.PutUint64(key, uint64(part.Index))
+	copy(key[8:], word[:])
+	sum := sha256.Sum256(key)
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	m := uint64(len(b.Bits) * 8)
+	positions := make([]uint64, b.Hashes)
+	for i := range positions {
+		positions[i] = (h1 + uint64(i)*h2) % m
+	}
+	return positions
+}
+
+// Digest Hash of every (index, word or share) pair held by the robot, in index order.
+// Two robots with the same digest hold exactly the same parts.
+func Digest(parts []SecretPart) []byte {
+	sorted := make([]SecretPart, len(parts))
+	copy(sorted, parts)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+	return digestOrdered(sorted)
+}
+
+// digestOrdered Digest of parts already ordered by index
+func digestOrdered(sorted []SecretPart) []byte {
+	hash := sha256.New()
+	buffer := make([]byte, 8)
+	for _, part := range sorted {
+		binary.BigEndian.PutUint64(buffer, uint64(part.Index))
+		hash.Write(buffer)
+		binary.BigEndian.PutUint64(buffer, uint64(len(part.Word)))
+		hash.Write(buffer)
+		hash.Write([]byte(part.Word))
+		hash.Write(part.Data)
+	}
+	return hash.Sum(nil)
+}
@@ -0,0 +1,107 @@
+package robot
+
+import (
+	"math"
+	"math/rand"
+	"robots/pkg/errors"
+)
+
+type Distribution string
+
+const (
+	RandomDistribution     Distribution = "random"      // Each part on a uniformly random robot
+	RoundRobinDistribution Distribution = "round-robin" // Part i on robot i modulo the number of robots
+	ChunksDistribution     Distribution = "chunks"      // Contiguous runs of parts on each robot
+	ZipfDistribution       Distribution = "zipf"        // Skewed, the lowest robots hold most parts
+	SingleDistribution     Distribution = "single"      // Every part on robot 0, the worst case
+)
+
+// ParseDistribution Reads the distribution setting (random by default)
+func ParseDistribution(value string) (Distribution, error) {
+	switch Distribution(value) {
+	case "", RandomDistribution:
+		return RandomDistribution, nil
+	case RoundRobinDistribution, ChunksDistribution, ZipfDistribution, SingleDistribution:
+		return Distribution(value), nil
+	default:
+		return RandomDistribution, errors.ErrUnknownDistribution
+	}
+}
+
+// Distribution Strategy spreading the parts, shares and fragments are dealt one per robot in turn by default
+func (s SecretManager) Distribution() Distribution {
+	distribution, _ := ParseDistribution(s.Config.Distribution)
+	if scheme, _ := ParseScheme(s.Config.SecretScheme); scheme.Coded() && s.Config.Distribution == "" {
+		return RoundRobinDistribution
+	}
+	return distribution
+}
+
+// Holders Chooses the robots initially holding each part.
+// The strategy picks the first holder, the replicas go to the robots following it,
+// so every part is on replication distinct robots.
+func (d Distribution) Holders(parts, nbrOfRobots, replication int, zipfExponent float64, rng *rand.Rand) [][]ID {
+	var zipf *rand.Zipf
+	if d == ZipfDistribution && nbrOfRobots > 1 {
+		zipf = rand.NewZipf(rng, zipfExponent, 1, uint64(nbrOfRobots-1))
+	}
+	holders := make([][]ID, parts)
+	for index := range holders {
+		var first int
+		switch d {
+		case RoundRobinDistribution:
+			first = index % nbrOfRobots
+		case ChunksDistribution:
+			first = index * nbrOfRobots / parts
+		case ZipfDistribution:
+			if zipf != nil {
+				first = int(zipf.Uint64())
+			}
+		case SingleDistribution:
+			first = 0
+		default:
+			first = rng.Intn(nbrOfRobots)
+		}
+		for replica := 0; replica < max(1, replication); replica++ {
+			holders[index] = append(holders[index], ID((first+replica)%nbrOfRobots))
+		}
+	}
+	return holders
+}
+
+// DistributionReport How the parts were initially spread among the robots
+type DistributionReport struct {
+	PartsPerRobot []int
+	Empty         int // Robots holding no part
+	Min           int
+	Max           int
+	Mean          float64
+	StdDev        float64
+}
+
+func NewDistributionReport(robots []*Robot) DistributionReport {
+	report := DistributionReport{PartsPerRobot: make([]int, len(robots))}
+	if len(robots) == 0 {
+		return report
+	}
+	total := 0
+	for i, r := range robots {
+		known := r.Snapshot().Known()
+		report.PartsPerRobot[i] = known
+		total += known
+		if known == 0 {
+			report.Empty++
+		}
+		if i == 0 || known < report.Min {
+			report.Min = known
+		}
+		report.Max = max(report.Max, known)
+	}
+	report.Mean = float64(total) / float64(len(robots))
+	variance := 0.0
+	for _, known := range report.PartsPerRobot {
+		variance += (float64(known) - report.Mean) * (float64(known) - report.Mean)
+	}
+	report.StdDev = math.Sqrt(variance / float64(len(robots)))
+	return report
+}
@@ -0,0 +1,203 @@
+package robot
+
+import (
+	"crypto/sha256"
+	"math/bits"
+	pb "robots/proto"
+	"sync"
+	"sync/atomic"
+
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// partIndex Index-keyed view over the parts held by a robot.
+// Parts are stored in a dense array at their index, and a bitset tells which
+// indexes are held, so lookups, merges and completeness checks are O(1).
+// The highest index and the number of distinct indexes are maintained on
+// every add, which gives the number of gaps without scanning the parts.
+//
+// The robot also keeps the arrival log of its parts: both are only written by
+// MergeSecretPart, under the write lock, so readers just take the read lock.
+// A nil index is an empty one.
+type partIndex struct {
+	parts    []SecretPart
+	present  []uint64
+	count    int // Number of distinct indexes held
+	maxIndex int // Highest index held, -1 when empty
+	view     atomic.Pointer[orderedView]
+}
+
+// orderedView Distinct parts held, ordered by index, for a given number of parts.
+// Parts are never removed nor changed, so a view is built once per number of
+// parts and shared by every snapshot taken until the next merge.
+// It must never be modified.
+type orderedView struct {
+	count        int
+	parts        []SecretPart
+	indexes      []int64
+	once         sync.Once
+	digest       []byte
+	verifyOnce   sync.Once
+	verification verification
+}
+
+// verification Outcome of Snapshot.Verify, computed once per view
+type verification struct {
+	expected, actual [sha256.Size]byte
+	ok               bool
+}
+
+// emptyView The view of a robot without any part
+var emptyView = &orderedView{}
+
+// Digest Hash of the parts of the view, computed on first use
+func (v *orderedView) Digest() []byte {
+	v.once.Do(func() {
+		v.digest = digestOrdered(v.parts)
+	})
+	return v.digest
+}
+
+func newPartIndex() *partIndex {
+	return &partIndex{maxIndex: -1}
+}
+
+func (p *partIndex) get(index int) (SecretPart, bool) {
+	if p == nil || index < 0 || index/64 >= len(p.present) || p.present[index/64]&(1<<(index%64)) == 0 {
+		return SecretPart{}, false
+	}
+	return p.parts[index], true
+}
+
+// add Indexes a part, returns false when the index was already held
+func (p *partIndex) add(part SecretPart) bool {
+	if _, ok := p.get(part.Index); ok {
+		return false
+	}
+	if part.Index >= len(p.parts) {
+		parts := make([]SecretPart, max(part.Index+1, 2*len(p.parts)))
+		copy(parts, p.parts)
+		p.parts = parts
+		present := make([]uint64, (len(parts)+63)/64)
+		copy(present, p.present)
+		p.present = present
+	}
+	p.parts[part.Index] = part
+	p.present[part.Index/64] |= 1 << (part.Index % 64)
+	p.count++
+	p.maxIndex = max(p.maxIndex, part.Index)
+	return true
+}
+
+// ordered Returns the view over the parts held, built again only when parts were added since.
+// Safe under the read lock: concurrent readers may build the same view, any of them is kept.
+func (p *partIndex) ordered() *orderedView {
+	if p == nil || p.count == 0 {
+		return emptyView
+	}
+	if view := p.view.Load(); view != nil && view.count == p.count {
+		return view
+	}
+	view := &orderedView{count: p.count, parts: make([]SecretPart, 0, p.count), indexes: make([]int64, 0, p.count)}
+	p.each(func(part SecretPart) {
+		view.parts = append(view.parts, part)
+		view.indexes = append(view.indexes, int64(part.Index))
+	})
+	p.view.Store(view)
+	return view
+}
+
+// gaps Number of indexes missing below the highest index held
+func (p *partIndex) gaps() int {
+	return p.maxIndex + 1 - p.count
+}
+
+// each Calls fn for every index held, in increasing order
+func (p *partIndex) each(fn func(part SecretPart)) {
+	if p == nil {
+		return
+	}
+	for i, block := range p.present {
+		for block != 0 {
+			index := i*64 + bits.TrailingZeros64(block)
+			fn(p.parts[index])
+			block &= block - 1
+		}
+	}
+}
+
+// ranges Intervals of consecutive indexes held, read a run of bits at a time from the bitset
+func (p *partIndex) ranges() IndexRanges {
+	if p == nil {
+		return nil
+	}
+	var ranges IndexRanges
+	for i, block := range p.present {
+		for block != 0 {
+			start := bits.TrailingZeros64(block)
+			length := bits.TrailingZeros64(^(block >> start))
+			first := i*64 + start
+			if last := len(ranges) - 1; last >= 0 && ranges[last].End+1 == first {
+				ranges[last].End = first + length - 1
+			} else {
+				ranges = append(ranges, IndexRange{Start: first, End: first + length - 1})
+			}
+			block &^= (1<<length - 1) << start
+		}
+	}
+	return ranges
+}
+
+// bitmap The bitset itself, cut after the highest index held
+func (p *partIndex) bitmap() IndexBitmap {
+	if p == nil || p.count == 0 {
+		return nil
+	}
+	bitmap := make(IndexBitmap, p.maxIndex/8+1)
+	for i := range bitmap {
+		bitmap[i] = byte(p.present[i/8] >> (i % 8 * 8))
+	}
+	return bitmap
+}
+
+// encode Fills the summary with the indexes held in the given exact version.
+// A compact summary is given the version whose encoding is the smallest.
+func (p *partIndex) encode(summary *pb.GossipSummary, version pb.SummaryVersion) {
+	var ranges IndexRanges
+	if version == pb.SummaryVersion_RANGES || version == pb.SummaryVersion_COMPACT {
+		ranges = p.ranges()
+	}
+	if version == pb.SummaryVersion_COMPACT {
+		version = p.smallest(len(ranges))
+	}
+	summary.Version = version
+	switch version {
+	case pb.SummaryVersion_RANGES:
+		summary.Ranges = lo.Map(ranges, func(item IndexRange, _ int) *pb.IndexRange {
+			return &pb.IndexRange{Start: int64(item.Start), End: int64(item.End)}
+		})
+	case pb.SummaryVersion_BITMAP:
+		summary.Bitmap = p.bitmap()
+	default:
+		summary.Indexes = p.ordered().indexes
+	}
+}
+
+// smallest The exact version with the fewest bytes on the wire, given the number of intervals held.
+// Every index is sized like the highest one, which is enough to compare the encodings.
+func (p *partIndex) smallest(ranges int) pb.SummaryVersion {
+	if p == nil || p.count == 0 {
+		return pb.SummaryVersion_INDEX_LIST
+	}
+	index := protowire.SizeVarint(uint64(p.maxIndex))
+	smallest, size := pb.SummaryVersion_INDEX_LIST, p.count*index
+	// Tag and length of each interval, then its two bounds
+	if rangesSize := ranges * (2 + 2*(1+index)); rangesSize < size {
+		smallest, size = pb.SummaryVersion_RANGES, rangesSize
+	}
+	if bitmapSize := p.maxIndex/8 + 1; bitmapSize < size {
+		smallest = pb.SummaryVersion_BITMAP
+	}
+	return smallest
+}
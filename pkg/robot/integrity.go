@@ -0,0 +1,125 @@
+package robot
+
+import (
+	"crypto/sha256"
+	"strings"
+)
+
+// SecretIntegrity What every robot is given about the whole secret when it is split,
+// so it can verify its reconstruction exactly instead of relying on the
+// end-of-secret marker. The zero value means no integrity is known.
+//
+// With a coded scheme, the hash of the secret would let a robot holding fewer
+// shares than the threshold confirm a guess offline: each share or fragment is
+// committed to instead, and the secret is verified through the parts it is
+// rebuilt from.
+type SecretIntegrity struct {
+	WordCount   int                 // Number of tokens
+	Hash        [sha256.Size]byte   // SHA-256 of the tokens joined back, the words by a single space, zero with a coded scheme
+	Commitments [][sha256.Size]byte // SHA-256 of each share or fragment with its index, by index, nil for words
+	Tokenizer   Tokenizer           // Nil for words
+	Scheme      Scheme
+	Threshold   int // Number of distinct shares or fragments needed with a coded scheme, 0 for words
+	Parts       int // Number of indexes the secret is split into: tokens, shares or fragments
+}
+
+// MaxPartIndex Highest index accepted when the integrity is unknown,
+// so a forged index can't make the robot allocate an unbounded index
+const MaxPartIndex = 1<<16 - 1
+
+func NewSecretIntegrity(words []string) SecretIntegrity {
+	return NewTokenIntegrity(WordTokenizer{}, words)
+}
+
+func NewTokenIntegrity(tokenizer Tokenizer, tokens []string) SecretIntegrity {
+	return SecretIntegrity{WordCount: len(tokens), Hash: sha256.Sum256([]byte(tokenizer.Join(tokens))), Tokenizer: tokenizer, Parts: len(tokens)}
+}
+
+// Covers Tells if the index is one of the parts of the secret.
+// Without integrity, any index up to MaxPartIndex is accepted.
+func (i SecretIntegrity) Covers(index int) bool {
+	if i.Parts > 0 {
+		return index >= 0 && index < i.Parts
+	}
+	return index >= 0 && index <= MaxPartIndex
+}
+
+func (i SecretIntegrity) tokenizer() Tokenizer {
+	if i.Tokenizer == nil {
+		return WordTokenizer{}
+	}
+	return i.Tokenizer
+}
+
+// Known Tells if the integrity was distributed to the robot
+func (i SecretIntegrity) Known() bool {
+	return i.WordCount > 0
+}
+
+// HasEnoughParts Every index of the secret is held, whatever the words.
+// With a coded scheme, the secret is decodable: at least threshold distinct shares or fragments are held.
+func (s Snapshot) HasEnoughParts(endOfSecret string) bool {
+	if s.integrity.Threshold > 0 {
+		return s.known >= s.integrity.Threshold
+	}
+	if !s.integrity.Known() {
+		return s.known > 0 && s.maxIndex+1 == s.known && strings.HasSuffix(s.lastWord, endOfSecret)
+	}
+	return s.known == s.integrity.WordCount && s.maxIndex+1 == s.known
+}
+
+// Commit Commitment to a share or fragment, bound to its index
+func Commit(part SecretPart) [sha256.Size]byte {
+	var commitment [sha256.Size]byte
+	copy(commitment[:], Digest([]SecretPart{part}))
+	return commitment
+}
+
+// Verify Hashes the reconstructed secret and compares it with the expected hash.
+// With commitments, every part held is compared with its commitment instead,
+// and the first mismatch is returned.
+// The outcome is computed once per version of the robot and shared by its
+// snapshots: the integrity of a robot never changes, and its parts only
+// change with the version.
+func (s Snapshot) Verify() (expected, actual [sha256.Size]byte, ok bool) {
+	if s.known == 0 {
+		// The empty view is shared by every robot, whatever its integrity
+		return s.verify()
+	}
+	view := s.view()
+	view.verifyOnce.Do(func() {
+		expected, actual, ok := s.verify()
+		view.verification = verification{expected: expected, actual: actual, ok: ok}
+	})
+	return view.verification.expected, view.verification.actual, view.verification.ok
+}
+
+func (s Snapshot) verify() (expected, actual [sha256.Size]byte, ok bool) {
+	if s.integrity.Commitments != nil {
+		for _, part := range s.Ordered() {
+			actual = Commit(part)
+			if part.Index >= len(s.integrity.Commitments) || actual != s.integrity.Commitments[part.Index] {
+				return s.commitment(part.Index), actual, false
+			}
+		}
+		return expected, actual, true
+	}
+	actual = sha256.Sum256([]byte(s.BuildSecret()))
+	return s.integrity.Hash, actual, actual == s.integrity.Hash
+}
+
+func (s Snapshot) commitment(index int) [sha256.Size]byte {
+	if index >= len(s.integrity.Commitments) {
+		return [sha256.Size]byte{}
+	}
+	return s.integrity.Commitments[index]
+}
+
+// VerificationFailed Enough parts are held but the reconstructed secret doesn't match its hash
+func (s Snapshot) VerificationFailed() bool {
+	if !s.integrity.Known() || !s.HasEnoughParts("") {
+		return false
+	}
+	_, _, ok := s.Verify()
+	return !ok
+}
@@ -0,0 +1,199 @@
+package robot
+
+import "sync"
+
+// LinkKind Channels of a robot covered by global snapshots
+type LinkKind string
+
+const (
+	SummaryLink LinkKind = "summary"
+	UpdateLink  LinkKind = "update"
+	RumorLink   LinkKind = "rumor" // Only when rumor mongering is enabled
+)
+
+// Link Incoming channel of a robot, as seen from a single sender
+type Link struct {
+	From ID
+	Kind LinkKind
+}
+
+// RecordedMessage Message in transit on a link when a global snapshot was taken
+type RecordedMessage struct {
+	From  ID           `json:"from"`
+	Kind  LinkKind     `json:"kind"`
+	Parts []SecretPart `json:"parts,omitempty"`
+}
+
+// LocalSnapshot Part of a global snapshot recorded by a single robot:
+// its own parts and the messages in transit on its incoming links.
+type LocalSnapshot struct {
+	ID       uint64            `json:"id"`
+	RobotID  ID                `json:"robot_id"`
+	Lamport  uint64            `json:"lamport"`
+	Parts    []SecretPart      `json:"parts"`
+	InFlight []RecordedMessage `json:"in_flight"`
+}
+
+// PendingMarker Marker that still has to be sent to a peer
+type PendingMarker struct {
+	To   ID
+	Kind LinkKind
+}
+
+// recording Chandy-Lamport state of a robot.
+// Every summary, update and rumor carries the last snapshot recorded by its
+// sender (its epoch), which acts as a marker piggybacked on the message.
+// Several workers write to the same links, so the epoch is read and the
+// message sent under the sender's outbox (see Outbox), and the robot never
+// records a snapshot in between: a link carries non-decreasing epochs, and
+// once a message of epoch S is received from a peer, everything that follows
+// was sent after the peer recorded S. Explicit markers close the links without traffic.
+// Merkle exchanges only carry hashes, the parts they find missing are sent as updates.
+type recording struct {
+	mu          sync.Mutex
+	nbrOfRobots int
+	links       []LinkKind
+	epoch       uint64
+	current     *LocalSnapshot
+	open        map[Link]struct{}
+	markers     []PendingMarker
+	completed   []LocalSnapshot
+}
+
+// EnableSnapshots Lets the robot take part in global snapshots of nbrOfRobots robots.
+// The summary and update links are recorded, along with the given ones.
+func (r *Robot) EnableSnapshots(nbrOfRobots int, links ...LinkKind) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording = &recording{nbrOfRobots: nbrOfRobots, links: append([]LinkKind{SummaryLink, UpdateLink}, links...)}
+}
+
+// Outbox Runs send while the robot can't record a global snapshot.
+// Every message carrying the snapshot epoch must be built and sent within send,
+// so it leaves before the robot records the next snapshot: the links stay FIFO
+// for the epochs even though several workers write to them. send must not block.
+func (r *Robot) Outbox(send func()) {
+	r.outbox.Lock()
+	defer r.outbox.Unlock()
+	send()
+}
+
+// SnapshotEpoch Returns the last global snapshot recorded by the robot, to be set on every message
+func (r *Robot) SnapshotEpoch() uint64 {
+	if r.recording == nil {
+		return 0
+	}
+	r.recording.mu.Lock()
+	defer r.recording.mu.Unlock()
+	return r.recording.epoch
+}
+
+// StartSnapshot Records the robot's state as the initiator of a global snapshot.
+// Returns false when the snapshot was already recorded.
+func (r *Robot) StartSnapshot(id uint64) bool {
+	if r.recording == nil {
+		return false
+	}
+	r.outbox.Lock()
+	defer r.outbox.Unlock()
+	r.recording.mu.Lock()
+	defer r.recording.mu.Unlock()
+	if id <= r.recording.epoch {
+		return false
+	}
+	r.record(id)
+	return true
+}
+
+// ObserveSnapshot Must be called for every summary, update or rumor received, before processing it.
+// A marker, or a message of a more recent epoch, records the robot's state if
+// not done yet and closes the link. Other messages received on an open link
+// are recorded as in transit.
+func (r *Robot) ObserveSnapshot(from ID, kind LinkKind, epoch uint64, parts []SecretPart) {
+	if r.recording == nil || from == r.ID {
+		return
+	}
+	r.outbox.Lock()
+	defer r.outbox.Unlock()
+	r.recording.mu.Lock()
+	defer r.recording.mu.Unlock()
+	if epoch > r.recording.epoch {
+		r.record(epoch)
+	}
+	current := r.recording.current
+	if current == nil {
+		return
+	}
+	link := Link{From: from, Kind: kind}
+	if _, ok := r.recording.open[link]; !ok {
+		return
+	}
+	if epoch >= current.ID {
+		delete(r.recording.open, link)
+		r.completeIfClosed()
+		return
+	}
+	current.InFlight = append(current.InFlight, RecordedMessage{From: from, Kind: kind, Parts: parts})
+}
+
+// PendingMarkers Returns and forgets the markers to send, see RequeueMarkers
+func (r *Robot) PendingMarkers() (uint64, []PendingMarker) {
+	if r.recording == nil {
+		return 0, nil
+	}
+	r.recording.mu.Lock()
+	defer r.recording.mu.Unlock()
+	markers := r.recording.markers
+	r.recording.markers = nil
+	return r.recording.epoch, markers
+}
+
+// RequeueMarkers Keeps the markers that couldn't be sent for a later attempt
+func (r *Robot) RequeueMarkers(epoch uint64, markers []PendingMarker) {
+	r.recording.mu.Lock()
+	defer r.recording.mu.Unlock()
+	if epoch == r.recording.epoch {
+		r.recording.markers = append(r.recording.markers, markers...)
+	}
+}
+
+// CompletedSnapshots Returns and forgets the local snapshots whose links are all closed
+func (r *Robot) CompletedSnapshots() []LocalSnapshot {
+	if r.recording == nil {
+		return nil
+	}
+	r.recording.mu.Lock()
+	defer r.recording.mu.Unlock()
+	completed := r.recording.completed
+	r.recording.completed = nil
+	return completed
+}
+
+// record Saves the robot's state and opens every incoming link.
+// A snapshot still in progress is abandoned. Must be called with the recording lock held.
+func (r *Robot) record(id uint64) {
+	rec := r.recording
+	rec.epoch = id
+	rec.current = &LocalSnapshot{ID: id, RobotID: r.ID, Lamport: r.Clock.Tick(), Parts: r.Snapshot().Ordered()}
+	rec.open = make(map[Link]struct{})
+	rec.markers = nil
+	for i := 0; i < rec.nbrOfRobots; i++ {
+		if ID(i) == r.ID {
+			continue
+		}
+		for _, kind := range rec.links {
+			rec.open[Link{From: ID(i), Kind: kind}] = struct{}{}
+			rec.markers = append(rec.markers, PendingMarker{To: ID(i), Kind: kind})
+		}
+	}
+	r.completeIfClosed()
+}
+
+func (r *Robot) completeIfClosed() {
+	rec := r.recording
+	if len(rec.open) > 0 || rec.current == nil {
+		return
+	}
+	rec.completed = append(rec.completed, *rec.current)
+	rec.current = nil
+}
@@ -0,0 +1,177 @@
+package robot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"robots/pkg/errors"
+	pb "robots/proto"
+
+	"github.com/samber/lo"
+)
+
+type Reconciliation string
+
+const (
+	SummaryReconciliation Reconciliation = "summary" // Full summaries (list, ranges, bitmap or bloom)
+	MerkleReconciliation  Reconciliation = "merkle"  // Top-down comparison of Merkle trees
+)
+
+// ParseReconciliation Reads the reconciliation setting (summaries by default)
+func ParseReconciliation(value string) (Reconciliation, error) {
+	switch Reconciliation(value) {
+	case "", SummaryReconciliation:
+		return SummaryReconciliation, nil
+	case MerkleReconciliation:
+		return MerkleReconciliation, nil
+	default:
+		return SummaryReconciliation, errors.ErrUnknownReconciliation
+	}
+}
+
+// MerkleTree Hash tree over the index space of the secret, as in Dynamo or Cassandra.
+// Indexes are spread over a fixed number of leaves (index modulo leaves), so two
+// robots always build trees of the same shape without knowing the secret length.
+// A leaf hash is the XOR of its parts' hashes, which makes merges incremental
+// and independent of the arrival order. Inner nodes hash their two children.
+// Nodes are stored as a heap: the root is at 1 and the children of p at 2p and 2p+1.
+type MerkleTree struct {
+	leaves int
+	nodes  [][sha256.Size]byte
+}
+
+// IsValidMerkleLeaves The tree is complete, so the number of leaves is a power of two
+func IsValidMerkleLeaves(leaves int) bool {
+	return leaves > 0 && leaves&(leaves-1) == 0
+}
+
+func NewMerkleTree(leaves int, parts []SecretPart) *MerkleTree {
+	tree := &MerkleTree{leaves: leaves, nodes: make([][sha256.Size]byte, 2*leaves)}
+	for _, part := range parts {
+		tree.xorLeaf(part)
+	}
+	for position := leaves - 1; position >= 1; position-- {
+		tree.rehash(position)
+	}
+	return tree
+}
+
+// Add Updates the leaf of a new part and every node up to the root
+func (t *MerkleTree) Add(part SecretPart) {
+	position := t.xorLeaf(part)
+	for position /= 2; position >= 1; position /= 2 {
+		t.rehash(position)
+	}
+}
+
+func (t *MerkleTree) Leaves() int {
+	return t.leaves
+}
+
+// Leaf Position of the leaf holding the given index
+func (t *MerkleTree) Leaf(index int) int {
+	return t.leaves + index%t.leaves
+}
+
+func (t *MerkleTree) IsLeaf(position int) bool {
+	return position >= t.leaves && position < 2*t.leaves
+}
+
+func (t *MerkleTree) Hash(position int) []byte {
+	if position < 1 || position >= len(t.nodes) {
+		return nil
+	}
+	hash := t.nodes[position]
+	return hash[:]
+}
+
+func (t *MerkleTree) xorLeaf(part SecretPart) int {
+	position := t.Leaf(part.Index)
+	buffer := make([]byte, 8, 8+len(part.Word)+len(part.Data))
+	binary.BigEndian.PutUint64(buffer, uint64(part.Index))
+	hash := sha256.Sum256(append(append(buffer, part.Word...), part.Data...))
+	for i := range hash {
+		t.nodes[position][i] ^= hash[i]
+	}
+	return position
+}
+
+func (t *MerkleTree) rehash(position int) {
+	left, right := t.nodes[2*position], t.nodes[2*position+1]
+	t.nodes[position] = sha256.Sum256(append(left[:], right[:]...))
+}
+
+// EnableMerkle Builds the Merkle tree of the robot from its current parts.
+// The tree is then maintained by MergeSecretPart.
+func (r *Robot) EnableMerkle(leaves int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var parts []SecretPart
+	r.index.each(func(part SecretPart) {
+		parts = append(parts, part)
+	})
+	r.merkle = NewMerkleTree(leaves, parts)
+}
+
+// MerkleLeaves Returns the number of leaves of the tree, 0 when Merkle reconciliation is disabled
+func (r *Robot) MerkleLeaves() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.merkle == nil {
+		return 0
+	}
+	return r.merkle.Leaves()
+}
+
+// MerkleNodes Returns the hashes of the robot's tree at the given positions
+func (r *Robot) MerkleNodes(positions []int) []*pb.MerkleNode {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.merkle == nil {
+		return nil
+	}
+	return lo.Map(positions, func(position int, _ int) *pb.MerkleNode {
+		return &pb.MerkleNode{Position: uint32(position), Hash: r.merkle.Hash(position)}
+	})
+}
+
+// DiffMerkleNodes Compares nodes of a peer with the robot's own tree.
+// Returns the differing inner nodes and the differing leaves.
+func (r *Robot) DiffMerkleNodes(nodes []*pb.MerkleNode) (inner []int, leaves []int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.merkle == nil {
+		return nil, nil
+	}
+	for _, node := range nodes {
+		position := int(node.Position)
+		own := r.merkle.Hash(position)
+		if own == nil || string(own) == string(node.Hash) {
+			continue
+		}
+		if r.merkle.IsLeaf(position) {
+			leaves = append(leaves, position)
+		} else {
+			inner = append(inner, position)
+		}
+	}
+	return inner, leaves
+}
+
+// LeafParts Returns the parts stored under the given leaves
+func (r *Robot) LeafParts(leaves []int) []SecretPart {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.merkle == nil {
+		return nil
+	}
+	wanted := lo.SliceToMap(leaves, func(leaf int) (int, struct{}) {
+		return leaf, struct{}{}
+	})
+	var parts []SecretPart
+	r.index.each(func(part SecretPart) {
+		if _, ok := wanted[r.merkle.Leaf(part.Index)]; ok {
+			parts = append(parts, part)
+		}
+	})
+	return parts
+}
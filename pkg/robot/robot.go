@@ -1,12 +1,14 @@
 package robot
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"math/rand"
 	"robots/internal/conf"
+	"robots/pkg/clocks"
+	"robots/pkg/termination"
 	pb "robots/proto"
-	"sort"
-	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +28,24 @@ type ISecretManager interface {
 
 type SecretManager struct {
 	Config conf.Config
+	Clocks []*clocks.Physical // Simulated clock of each robot, the real clock when missing
+	Rand   *rand.Rand         // Random source of the holders and of the rumor losses, randomly seeded when missing
+}
+
+// Clock Returns the simulated clock of the robot, nil for the real clock
+func (s SecretManager) Clock(id ID) *clocks.Physical {
+	if id.ToInt() >= len(s.Clocks) {
+		return nil
+	}
+	return s.Clocks[id]
+}
+
+// random Returns the random source of the manager, a randomly seeded one when missing
+func (s SecretManager) random() *rand.Rand {
+	if s.Rand == nil {
+		return rand.New(rand.NewSource(rand.Int63()))
+	}
+	return s.Rand
 }
 
 type ID int
@@ -38,18 +58,48 @@ func (id ID) ToInt() int {
 // Because at any moment robot exchange with others
 // They should have their own snapshot
 type Robot struct {
-	mu            sync.RWMutex
-	ID            ID // Index of the robots
-	SecretParts   []SecretPart
-	GossipSummary chan []byte // Represents a channel of current indexes of robots
-	GossipUpdate  chan []byte // Represents a channel of missing secretParts
-	LastUpdatedAt time.Time   // Necessary to know if no words have been received since a long time
+	mu               sync.RWMutex
+	ID               ID           // Index of the robots
+	arrivals         []SecretPart // Parts in arrival order, only appended by MergeSecretPart
+	GossipSummary    chan []byte  // Represents a channel of current indexes of robots
+	GossipUpdate     chan []byte  // Represents a channel of missing secretParts
+	GossipRumor      chan []byte  // Represents a channel of hot rumors pushed by peers
+	RumorFeedback    chan []byte  // Represents a channel of rumors that peers already knew
+	GossipMerkle     chan []byte  // Represents a channel of Merkle reconciliation steps
+	LastUpdatedAt    time.Time    // Necessary to know if no words have been received since a long time
+	Rumors           *Rumors      // Nil unless rumor mongering is enabled
+	peerIndexes      map[ID]PartSet
+	merkle           *MerkleTree // Nil unless Merkle reconciliation is enabled
+	index            *partIndex  // Maintained by MergeSecretPart
+	version          uint64      // Number of parts merged, see Snapshot
+	vector           VersionVector
+	Clock            *clocks.Lamport  // Ticks on every message sent and event emitted by the robot, shared by the secrets it hosts
+	Time             *clocks.Physical // Simulated wall clock of the robot, nil for the real clock
+	Termination      termination.SafraState
+	TerminationToken chan []byte     // Represents a channel of termination detection tokens
+	recording        *recording      // Nil unless global snapshots are enabled
+	outbox           sync.Mutex      // Held while a message is stamped and sent, see Outbox
+	Integrity        SecretIntegrity // Distributed with the parts, zero when unknown
+	Secret           SecretID        // Secret the state is about, the channels are shared by every secret
+}
+
+// NewRobot Builds a robot holding the given parts, without any channel.
+// Parts can only be given through MergeSecretPart, which keeps the index in sync.
+func NewRobot(id ID, integrity SecretIntegrity, parts ...SecretPart) *Robot {
+	r := &Robot{ID: id, LastUpdatedAt: time.Now().UTC(), Integrity: integrity, index: newPartIndex(), vector: make(VersionVector), Clock: &clocks.Lamport{}}
+	for _, part := range parts {
+		r.MergeSecretPart(part)
+	}
+	return r
 }
 
 // SecretPart Represents a word and the position from the secret
 type SecretPart struct {
-	Index int // Index of the word
-	Word  string
+	Index    int // Index of the word
+	Word     string
+	Origin   ID     // Robot that held the part when the secret was split
+	Sequence uint64 // Position among the parts of the origin, 0 when untracked
+	Data     []byte // Shamir share or Reed–Solomon fragment, nil for a word
 }
 
 func ChooseRobot(current *Robot, robots []*Robot) *Robot {
@@ -63,67 +113,145 @@ func ChooseRobot(current *Robot, robots []*Robot) *Robot {
 	return receiver
 }
 
-func (r *Robot) Indexes() []int64 {
-	return lo.Map(r.SecretParts, func(item SecretPart, _ int) int64 {
-		return int64(item.Index)
+// ChooseRobots picks up to k distinct robots other than the current one
+// The rng is the one of the calling worker, so a seeded run picks the same peers
+func ChooseRobots(current *Robot, robots []*Robot, k int, rng *rand.Rand) []*Robot {
+	others := lo.Filter(robots, func(item *Robot, _ int) bool {
+		return item.ID != current.ID
 	})
+	return Sample(rng, others, k)
+}
+
+// Sample Picks up to k distinct items in a random order drawn from the rng
+func Sample[T any](rng *rand.Rand, items []T, k int) []T {
+	k = max(0, min(k, len(items)))
+	sample := make([]T, k)
+	for i, j := range rng.Perm(len(items))[:k] {
+		sample[i] = items[j]
+	}
+	return sample
+}
+
+// Indexes Returns the distinct indexes held by the robot as sorted intervals, read straight from its index
+func (r *Robot) Indexes() IndexRanges {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.index.ranges()
 }
 
 // GetWordsToSend retourne tous les mots que le destinataire n'a pas encore
-func (r *Robot) GetWordsToSend(receiverIndexes []int) []SecretPart {
+// The receiver indexes can be in any encoding, a nil set means no index at all
+func (r *Robot) GetWordsToSend(receiverIndexes PartSet) []SecretPart {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var missing []SecretPart
-	for _, sp := range r.SecretParts {
-		if !lo.Contains(receiverIndexes, sp.Index) {
+	r.index.each(func(sp SecretPart) {
+		if receiverIndexes == nil || !receiverIndexes.Holds(sp) {
 			missing = append(missing, sp)
 		}
-	}
+	})
 	return missing
 }
 
+// RememberPeerIndexes Keeps the last indexes advertised by a peer in its summary
+func (r *Robot) RememberPeerIndexes(id ID, indexes PartSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.peerIndexes == nil {
+		r.peerIndexes = make(map[ID]PartSet)
+	}
+	r.peerIndexes[id] = indexes
+}
+
+// GetWordsToPush Returns the words a peer likely lacks, based on its last known summary.
+// Without any known summary, every word is pushed.
+func (r *Robot) GetWordsToPush(id ID) []SecretPart {
+	r.mu.RLock()
+	indexes := r.peerIndexes[id]
+	r.mu.RUnlock()
+	return r.GetWordsToSend(indexes)
+}
+
 // GetWords Returns words contained in the robot
-// Can be ordered or unordered by index of the initial secret
+// Can be ordered by index of the initial secret or in arrival order
 func (r *Robot) GetWords(ordered bool) []string {
-	parts := r.SecretParts
+	snapshot := r.Snapshot()
 	if ordered {
-		tmp := make([]SecretPart, len(parts))
-		copy(tmp, parts)
-		sort.Slice(tmp, func(i, j int) bool {
-			return tmp[i].Index < tmp[j].Index
-		})
-		parts = tmp
-	}
-	return lo.Map(parts, func(p SecretPart, _ int) string {
+		return snapshot.Words()
+	}
+	return lo.Map(snapshot.Parts, func(p SecretPart, _ int) string {
 		return p.Word
 	})
 }
 
 func (r *Robot) BuildSecret() string {
-	return strings.Join(r.GetWords(true), " ")
+	return r.Snapshot().BuildSecret()
 }
 
-// SplitSecret Initial sentences split into words
+// SplitSecret Initial sentences split into words, or into the tokens of the configured tokenizer
 func (s SecretManager) SplitSecret(word string) []string {
-	return strings.Fields(word)
+	return s.Tokenizer().Split(word)
+}
+
+// Tokenizer Splits the secret into words unless configured otherwise
+func (s SecretManager) Tokenizer() Tokenizer {
+	tokenizer, _ := ParseTokenizer(s.Config.Tokenizer, s.Config.ChunkSize)
+	return tokenizer
 }
 
-// CreateRobots Randomly assign words to n robots
+// CreateRobots Assign words to n robots, randomly unless another distribution is configured
 // Each of the contains word with indexes
+// With a coded scheme, the robots are dealt the shares or fragments of the secret instead
 func (s SecretManager) CreateRobots(words []string) []*Robot {
+	dissemination, _ := ParseDissemination(s.Config.Dissemination)
+	parts := s.SplitParts(words)
+	integrity := NewTokenIntegrity(s.Tokenizer(), words)
+	scheme, _ := ParseScheme(s.Config.SecretScheme)
+	integrity.Scheme, integrity.Threshold, integrity.Parts = scheme, s.Threshold(), len(parts)
+	if scheme.Coded() {
+		integrity.Hash, integrity.Commitments = [sha256.Size]byte{}, make([][sha256.Size]byte, len(parts))
+		for _, part := range parts {
+			integrity.Commitments[part.Index] = Commit(part)
+		}
+	}
+	rng := s.random()
 	robots := make([]*Robot, s.Config.NbrOfRobots)
 	for i := 0; i < s.Config.NbrOfRobots; i++ {
-		robots[i] = &Robot{
-			ID:            ID(i),
-			SecretParts:   []SecretPart{},
-			GossipSummary: make(chan []byte, s.Config.BufferSize),
-			GossipUpdate:  make(chan []byte, s.Config.BufferSize),
-			LastUpdatedAt: time.Now().UTC(),
+		r := NewRobot(ID(i), integrity)
+		// The clock is set before the initial parts are merged, so they are dated by it
+		r.Time = s.Clock(r.ID)
+		r.LastUpdatedAt = r.Time.Now()
+		r.GossipSummary, r.GossipUpdate, r.GossipRumor = make(chan []byte, s.Config.BufferSize), make(chan []byte, s.Config.BufferSize), make(chan []byte, s.Config.BufferSize)
+		r.RumorFeedback, r.GossipMerkle, r.TerminationToken = make(chan []byte, s.Config.BufferSize), make(chan []byte, s.Config.BufferSize), make(chan []byte, 1)
+		if dissemination.UsesRumors() {
+			r.Rumors = NewRumors(s.Config.RumorStopAfter, s.Config.RumorLossProbability, rand.New(rand.NewSource(rng.Int63())))
 		}
+		robots[i] = r
 	}
 
-	for index, word := range words {
-		key := rand.Intn(s.Config.NbrOfRobots)
-		secretPart := SecretPart{Index: index, Word: word}
-		robots[key].SecretParts = append(robots[key].SecretParts, secretPart)
+	sequences := make([]uint64, s.Config.NbrOfRobots)
+	holders := s.Distribution().Holders(len(parts), s.Config.NbrOfRobots, s.Config.ReplicationFactor, s.Config.ZipfExponent, rng)
+	for index, secretPart := range parts {
+		// The first holder is the origin of the part, the replicas are copies of it
+		key := holders[index][0]
+		sequences[key]++
+		secretPart.Origin, secretPart.Sequence = key, sequences[key]
+		// Initial parts are the very first rumors
+		for _, holder := range holders[index] {
+			robots[holder].MergeSecretPart(secretPart)
+		}
+	}
+	var links []LinkKind
+	if dissemination.UsesRumors() {
+		links = append(links, RumorLink)
+	}
+	for _, r := range robots {
+		r.EnableSnapshots(s.Config.NbrOfRobots, links...)
+	}
+	if reconciliation, _ := ParseReconciliation(s.Config.Reconciliation); reconciliation == MerkleReconciliation {
+		for _, r := range robots {
+			r.EnableMerkle(s.Config.MerkleLeaves)
+		}
 	}
 	return robots
 }
@@ -131,80 +259,83 @@ func (s SecretManager) CreateRobots(words []string) []*Robot {
 // MergeSecretPart merges a secret part into the robot's local state.
 // Invariants enforced:
 // - Monotonicity: secret parts are never removed.
-// - Uniqueness: a given index can map to only one word.
+// - Uniqueness: a given index can map to only one word (or share, or fragment).
 // - Idempotence: receiving the same (index, word) multiple times has no effect.
 // Behavior:
 // - If the index already exists with a different word, this is a fatal invariant violation and triggers a panic.
 // - If the index already exists with the same word, the update is ignored.
 // - If the part is new, it is appended and LastUpdatedAt is refreshed.
+// - A part whose index is not one of the secret's (see SecretIntegrity.Covers) is ignored.
+// - With rumor mongering enabled, a new part also becomes a hot rumor.
+// Returns true when the part was new.
 //
-// This method is the single entry point for mutating SecretParts
+// This method is the single entry point for mutating the parts
 // and acts as the consistency boundary of the Robot.
-func (r *Robot) MergeSecretPart(secretPart SecretPart) {
+func (r *Robot) MergeSecretPart(secretPart SecretPart) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	part, ok := findSecretPart(r.SecretParts, secretPart)
-	if ok && part.Word != secretPart.Word {
+	if r.index == nil {
+		r.index, r.vector = newPartIndex(), make(VersionVector)
+	}
+	part, ok := r.index.get(secretPart.Index)
+	if ok && (part.Word != secretPart.Word || !bytes.Equal(part.Data, secretPart.Data)) {
 		panic("invariant violation: same index, different word")
 	}
-	if ok {
-		return
+	if ok || !r.Integrity.Covers(secretPart.Index) {
+		return false
+	}
+	r.LastUpdatedAt = r.Time.Now()
+	r.arrivals = append(r.arrivals, secretPart)
+	r.index.add(secretPart)
+	r.vector.Add(secretPart.Origin, secretPart.Sequence)
+	r.version++
+	if r.Rumors != nil {
+		r.Rumors.Heat(secretPart.Index)
 	}
-	r.LastUpdatedAt = time.Now().UTC()
-	r.SecretParts = append(r.SecretParts, secretPart)
+	if r.merkle != nil {
+		r.merkle.Add(secretPart)
+	}
+	return true
 }
 
-func findSecretPart(secretParts []SecretPart, secretPart SecretPart) (SecretPart, bool) {
-	return lo.Find(secretParts, func(item SecretPart) bool {
-		return item.Index == secretPart.Index
-	})
+// GetSecretParts Returns the parts matching the given indexes
+func (r *Robot) GetSecretParts(indexes []int) []SecretPart {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var parts []SecretPart
+	for _, index := range lo.Uniq(indexes) {
+		if part, ok := r.index.get(index); ok {
+			parts = append(parts, part)
+		}
+	}
+	return parts
 }
 
 // IsSecretCompleted reports whether the robot has fully reconstructed the secret.
 // The secret is considered complete if:
 // - all indexes from 0 to the highest index are present (no gaps),
 // - and the last word ends with the given end-of-secret marker.
+// When the integrity of the secret was distributed, the number of words and
+// the hash of the reconstructed secret are checked instead of the marker.
 // This prevents false positives caused by partial, unordered, or duplicated gossip messages.
 func (r *Robot) IsSecretCompleted(endOfSecret string) bool {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	parts := r.SecretParts
-	if len(parts) == 0 {
-		return false
-	}
-
-	maxIndex := -1
-	indexes := make(map[int]struct{}, len(parts))
-	for _, p := range parts {
-		indexes[p.Index] = struct{}{}
-		if p.Index > maxIndex {
-			maxIndex = p.Index
-		}
-	}
-
-	// Check all indexes are present
-	for i := 0; i <= maxIndex; i++ {
-		if _, ok := indexes[i]; !ok {
-			return false // Missing word !
-		}
-	}
-
-	// Check end marker
-	words := r.GetWords(true)
-	return strings.HasSuffix(words[len(words)-1], endOfSecret)
+	return r.Snapshot().IsSecretCompleted(endOfSecret)
 }
 
 func FromSecretPartsPb(secretPartsPb []*pb.SecretPart) []SecretPart {
 	return lo.Map(secretPartsPb, func(item *pb.SecretPart, _ int) SecretPart {
-		return SecretPart{Index: int(item.Index), Word: item.Word}
+		return SecretPart{Index: int(item.Index), Word: item.Word, Origin: ID(item.OriginId), Sequence: item.Sequence, Data: item.Data}
 	})
 }
 
 func ToSecretPartsPb(secretParts []SecretPart) []*pb.SecretPart {
 	return lo.Map(secretParts, func(item SecretPart, _ int) *pb.SecretPart {
 		return &pb.SecretPart{
-			Index: int64(item.Index),
-			Word:  item.Word,
+			Index:    int64(item.Index),
+			Word:     item.Word,
+			OriginId: int32(item.Origin),
+			Sequence: item.Sequence,
+			Data:     item.Data,
 		}
 	})
 }
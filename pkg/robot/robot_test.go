@@ -1,9 +1,18 @@
 package robot
 
 import (
+	"fmt"
+	"math/rand"
+	"robots/internal/conf"
+	"robots/pkg/clocks"
+	pb "robots/proto"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -124,10 +133,7 @@ func TestRobot_IsSecretCompleted(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &Robot{
-				SecretParts:   tt.secretParts,
-				LastUpdatedAt: time.Now(),
-			}
+			r := NewRobot(0, SecretIntegrity{}, tt.secretParts...)
 
 			result := r.IsSecretCompleted(end)
 			ass.Equal(tt.expected, result,
@@ -139,22 +145,228 @@ func TestRobot_IsSecretCompleted(t *testing.T) {
 	}
 }
 
-func TestRobot_MergeSecretPart_Idempotence(t *testing.T) {
+func TestRobot_IsSecretCompletedWithIntegrity(t *testing.T) {
+	ass := assert.New(t)
+	words := []string{"Stop.", "here."}
+	r := &Robot{ID: 0, Integrity: NewSecretIntegrity(words)}
+
+	// The first word ends with the marker, only the word count tells the secret goes on
+	r.MergeSecretPart(SecretPart{Index: 0, Word: "Stop."})
+	ass.False(r.IsSecretCompleted("."))
+	ass.Equal(1, r.Snapshot().Missing("."))
+
+	r.MergeSecretPart(SecretPart{Index: 1, Word: "here."})
+	ass.True(r.IsSecretCompleted("."))
+	ass.True(r.IsSecretCompleted("!"), "the marker is ignored")
+	ass.False(r.Snapshot().VerificationFailed())
+
+	corrupted := &Robot{ID: 1, Integrity: NewSecretIntegrity(words)}
+	corrupted.MergeSecretPart(SecretPart{Index: 0, Word: "Stop."})
+	corrupted.MergeSecretPart(SecretPart{Index: 1, Word: "there."})
+	ass.False(corrupted.IsSecretCompleted("."))
+	ass.True(corrupted.Snapshot().VerificationFailed())
+	ass.Same(corrupted.Snapshot().view(), corrupted.Snapshot().view(), "verified once for the version, whatever the snapshot")
+	ass.False(corrupted.Snapshot().view().verification.ok)
+}
+
+func TestRobot_ReedSolomonSurvivesCrashedRobots(t *testing.T) {
+	ass := assert.New(t)
+	words := []string{"Hidden", "beneath", "the", "old", "oak", "tree."}
+	sm := SecretManager{Config: conf.Config{NbrOfRobots: 6, SecretScheme: string(ReedSolomonScheme), ErasureDataFragments: 3}}
+	robots := sm.CreateRobots(words)
+
+	// Robots 0 to 2 crash before gossiping: they held every data fragment
+	survivor := robots[3]
+	ass.False(survivor.IsSecretCompleted("."))
+	ass.Equal(2, survivor.Snapshot().Missing("."))
+	survivor.MergeSecretPart(robots[4].GetWordsToSend(nil)[0])
+	survivor.MergeSecretPart(robots[5].GetWordsToSend(nil)[0])
+	ass.True(survivor.IsSecretCompleted("."))
+	ass.Equal("Hidden beneath the old oak tree.", survivor.BuildSecret())
+}
+
+func TestRobot_ShamirSharesAreCommittedTo(t *testing.T) {
+	ass := assert.New(t)
+	words := []string{"Hidden", "treasure."}
+	sm := SecretManager{Config: conf.Config{NbrOfRobots: 3, SecretScheme: string(ShamirScheme), ShamirThreshold: 2}}
+	robots := sm.CreateRobots(words)
+	integrity := robots[0].Integrity
+	ass.Zero(integrity.Hash, "the hash of the secret would confirm a guess")
+	ass.Len(integrity.Commitments, 3)
+
+	share := robots[1].GetWordsToSend(nil)[0]
+	honest := NewRobot(0, integrity, robots[0].GetWordsToSend(nil)[0], share)
+	ass.True(honest.IsSecretCompleted("."))
+	ass.Equal("Hidden treasure.", honest.BuildSecret())
+
+	share.Data = append([]byte{share.Data[0] ^ 1}, share.Data[1:]...)
+	forged := NewRobot(0, integrity, robots[0].GetWordsToSend(nil)[0], share)
+	ass.False(forged.IsSecretCompleted("."))
+	ass.True(forged.Snapshot().VerificationFailed())
+	expected, actual, _ := forged.Snapshot().Verify()
+	ass.Equal(integrity.Commitments[share.Index], expected)
+	ass.Equal(Commit(share), actual)
+}
+
+func TestResidue_ShamirRobotsAboveTheThresholdAreNotMissing(t *testing.T) {
+	ass := assert.New(t)
+	words := []string{"Hidden", "treasure."}
+	sm := SecretManager{Config: conf.Config{NbrOfRobots: 3, SecretScheme: string(ShamirScheme), ShamirThreshold: 2}}
+	robots := sm.CreateRobots(words)
+	robots[0].MergeSecretPart(robots[1].GetWordsToSend(nil)[0])
+
+	residue := ComputeResidue(robots, 3)
+	ass.Equal(1, residue.CompletedRobots)
+	ass.Equal(2, residue.RobotsMissing)
+	ass.Equal(4, lo.Sum(lo.Values(residue.MissingByIndex)), "the share robot 0 lacks is not residue")
+}
+
+func TestResidue_ReedSolomonRobotsHoldingEnoughFragmentsAreNotMissing(t *testing.T) {
+	ass := assert.New(t)
+	words := []string{"Hidden", "beneath", "the", "old", "oak", "tree."}
+	sm := SecretManager{Config: conf.Config{NbrOfRobots: 6, SecretScheme: string(ReedSolomonScheme), ErasureDataFragments: 3}}
+	robots := sm.CreateRobots(words)
+	robots[3].MergeSecretPart(robots[4].GetWordsToSend(nil)[0])
+	robots[3].MergeSecretPart(robots[5].GetWordsToSend(nil)[0])
+
+	residue := ComputeResidue(robots, 6)
+	ass.Equal(6, residue.Parts)
+	ass.Equal(1, residue.CompletedRobots, "3 of the 6 fragments rebuild the secret")
+	ass.Equal(5, residue.RobotsMissing)
+	ass.Equal(25, lo.Sum(lo.Values(residue.MissingByIndex)))
+}
+
+func TestDistribution_Holders(t *testing.T) {
+	ass := assert.New(t)
+	rng := rand.New(rand.NewSource(1))
+
+	holders := ChunksDistribution.Holders(6, 3, 2, 0, rng)
+	ass.Equal([][]ID{{0, 1}, {0, 1}, {1, 2}, {1, 2}, {2, 0}, {2, 0}}, holders, "contiguous chunks, replicas on the next robots")
+	ass.Equal([][]ID{{0}, {1}, {2}, {0}}, RoundRobinDistribution.Holders(4, 3, 1, 0, rng))
+	for _, parts := range SingleDistribution.Holders(5, 3, 1, 0, rng) {
+		ass.Equal([]ID{0}, parts)
+	}
+	for _, distribution := range []Distribution{RandomDistribution, ZipfDistribution} {
+		for _, parts := range distribution.Holders(50, 6, 3, 1.5, rng) {
+			ass.Len(lo.Uniq(parts), 3, "replicas are on distinct robots")
+		}
+	}
+}
+
+func TestSecretManager_HoldersAreReproducibleWithTheSeed(t *testing.T) {
+	ass := assert.New(t)
+	words := strings.Fields("Hidden beneath the old oak tree, the treasure waits.")
+	config := conf.Config{NbrOfRobots: 5, Distribution: string(RandomDistribution), ReplicationFactor: 2}
+	holders := func(seed int64) [][]int64 {
+		sm := SecretManager{Config: config, Rand: rand.New(rand.NewSource(seed))}
+		return lo.Map(sm.CreateRobots(words), func(r *Robot, _ int) []int64 { return r.Snapshot().Indexes() })
+	}
+
+	ass.Equal(holders(42), holders(42))
+}
+
+func TestRobot_ChooseRobotsIsReproducibleWithTheSeed(t *testing.T) {
+	ass := assert.New(t)
+	robots := lo.Times(10, func(i int) *Robot { return &Robot{ID: ID(i)} })
+
+	first := ChooseRobots(robots[0], robots, 3, rand.New(rand.NewSource(42)))
+	second := ChooseRobots(robots[0], robots, 3, rand.New(rand.NewSource(42)))
+	ass.Equal(first, second, "the same seed picks the same peers")
+	ass.Len(lo.Uniq(first), 3)
+	ass.NotContains(first, robots[0], "a robot never gossips with itself")
+	ass.Len(ChooseRobots(robots[0], robots, 20, rand.New(rand.NewSource(42))), 9, "k is bounded by the other robots")
+}
+
+func TestRumors_LossesAreReproducibleWithTheSeed(t *testing.T) {
+	ass := assert.New(t)
+	removed := func() []int {
+		rumors := NewRumors(0, 0.5, rand.New(rand.NewSource(42)))
+		var removed []int
+		for index := 0; index < 20; index++ {
+			rumors.Heat(index)
+			if rumors.AlreadyKnown(index) {
+				removed = append(removed, index)
+			}
+		}
+		return removed
+	}
+
+	first := removed()
+	ass.NotEmpty(first)
+	ass.Less(len(first), 20)
+	ass.Equal(first, removed(), "the same seed loses interest in the same rumors")
+}
+
+func TestTokenizer_JoinReproducesTheSecret(t *testing.T) {
 	ass := assert.New(t)
-	r := &Robot{
-		ID:          0,
-		SecretParts: []SecretPart{},
+	secret := "key = \"värde\"\n\tport: 80\n\xff\x00end"
+	for _, setting := range []string{"runes", "bytes", "lines", "chunks"} {
+		tokenizer, err := ParseTokenizer(setting, 5)
+		ass.NoError(err)
+		ass.Equal(secret, tokenizer.Join(tokenizer.Split(secret)), setting)
+
+		sm := SecretManager{Config: conf.Config{NbrOfRobots: 2, Tokenizer: setting, ChunkSize: 5}}
+		robots := sm.CreateRobots(sm.SplitSecret(secret))
+		r := robots[0]
+		// Tokens which aren't valid UTF-8 go through the wire as data
+		for _, part := range FromSecretPartsPb(ToSecretPartsPb(robots[1].GetWordsToSend(nil))) {
+			r.MergeSecretPart(part)
+		}
+		ass.True(r.IsSecretCompleted("."), setting)
+		ass.Equal(secret, r.BuildSecret(), setting)
 	}
+	ass.Equal([]string{"a\n", "b"}, LineTokenizer{}.Split("a\nb"))
+	ass.Equal("key = \"värde\" port: 80 \xff\x00end", WordTokenizer{}.Join(WordTokenizer{}.Split(secret)), "words lose the whitespace")
+}
+
+func TestSecrets_ShareTheChannelsOfTheirHosts(t *testing.T) {
+	ass := assert.New(t)
+	files, err := ParseSecretFiles("bridge=/tmp/bridge.txt, blob = /tmp/blob.bin")
+	ass.NoError(err)
+	ass.Equal([]SecretFile{{ID: "bridge", Path: "/tmp/bridge.txt"}, {ID: "blob", Path: "/tmp/blob.bin"}}, files)
+	_, err = ParseSecretFiles("bridge=a,bridge=b")
+	ass.Error(err, "names are distinct")
+
+	sm := SecretManager{Config: conf.Config{NbrOfRobots: 3, BufferSize: 1}}
+	hosts := sm.CreateRobots([]string{"hello", "world."})
+	secrets := Secrets{"": hosts, "bridge": sm.CreateSecret("bridge", []string{"under", "the", "bridge."}, hosts)}
+	ass.Equal([]SecretID{"", "bridge"}, secrets.IDs())
+	other, ok := secrets.Robot("bridge", 2)
+	ass.True(ok)
+	ass.Equal(SecretID("bridge"), other.Secret)
+	ass.Equal(hosts[2].GossipSummary, other.GossipSummary)
+	other.Clock.Tick()
+	ass.Equal(uint64(1), hosts[2].Clock.Now(), "the host and its secrets tick one clock")
+	ass.Equal("bridge", other.Summary(0, 0).SecretId)
+	ass.Len(secrets.All(), 6)
+	_, ok = secrets.Robot("unknown", 0)
+	ass.False(ok)
+}
+
+func TestSecretManager_InitialPartsAreDatedByTheRobotClock(t *testing.T) {
+	ass := assert.New(t)
+	ahead := clocks.NewPhysical(time.Hour, 0)
+	sm := SecretManager{Config: conf.Config{NbrOfRobots: 2, BufferSize: 1, Distribution: string(SingleDistribution)}, Clocks: []*clocks.Physical{ahead}}
+	robots := sm.CreateRobots([]string{"hello", "world."})
+	ass.Same(ahead, robots[0].Time)
+	ass.WithinDuration(time.Now().Add(time.Hour), robots[0].Snapshot().LastUpdatedAt, time.Minute)
+	ass.Nil(robots[1].Time, "the real clock without a simulated one")
+	ass.Same(ahead, sm.CreateSecret("bridge", []string{"bridge."}, robots)[0].Time, "secrets share the clocks")
+}
+
+func TestRobot_MergeSecretPart_Idempotence(t *testing.T) {
+	ass := assert.New(t)
+	r := NewRobot(0, SecretIntegrity{})
 
 	part := SecretPart{Index: 0, Word: "hello"}
 
 	// Given Merging first time
 	r.MergeSecretPart(part)
-	ass.Equal(1, len(r.SecretParts))
+	ass.Len(r.Snapshot().Parts, 1)
 
 	// Given Merging the same part (idempotent)
 	r.MergeSecretPart(part)
-	ass.Equal(1, len(r.SecretParts))
+	ass.Len(r.Snapshot().Parts, 1)
 
 	// Given Merging a different part on the same index → panic expected
 	partConflict := SecretPart{Index: 0, Word: "world"}
@@ -162,3 +374,404 @@ func TestRobot_MergeSecretPart_Idempotence(t *testing.T) {
 		r.MergeSecretPart(partConflict)
 	})
 }
+
+func TestSnapshot_OrderedIsServedFromTheIndex(t *testing.T) {
+	ass := assert.New(t)
+	r := NewRobot(0, SecretIntegrity{}, SecretPart{Index: 2, Word: "c"}, SecretPart{Index: 0, Word: "a"})
+	first, second := r.Snapshot(), r.Snapshot()
+	ass.Equal([]int64{0, 2}, first.Indexes())
+	ass.Same(&first.Ordered()[0], &second.Ordered()[0], "no merge in between, the ordered parts are shared")
+	ass.Equal(Digest(first.Parts), first.Digest())
+
+	r.MergeSecretPart(SecretPart{Index: 1, Word: "b"})
+	ass.Equal([]int64{0, 1, 2}, r.Snapshot().Indexes())
+	ass.Equal([]int64{0, 2}, first.Indexes(), "an older snapshot keeps its view")
+	ass.NotEqual(first.Digest(), r.Snapshot().Digest())
+	ass.Empty((&Robot{}).Snapshot().Ordered())
+}
+
+func TestRobot_MergeSecretPart_DropsIndexesOutsideTheSecret(t *testing.T) {
+	ass := assert.New(t)
+	r := &Robot{Integrity: NewSecretIntegrity([]string{"a", "b."})}
+	ass.False(r.MergeSecretPart(SecretPart{Index: 2, Word: "c"}), "beyond the number of parts")
+	ass.False(r.MergeSecretPart(SecretPart{Index: -1, Word: "c"}))
+	ass.True(r.MergeSecretPart(SecretPart{Index: 1, Word: "b."}))
+
+	unknown := &Robot{}
+	ass.False(unknown.MergeSecretPart(SecretPart{Index: 1 << 40, Word: "huge"}), "bounded without integrity too")
+	ass.True(unknown.MergeSecretPart(SecretPart{Index: MaxPartIndex, Word: "last"}))
+	ass.Equal(IndexRanges{{MaxPartIndex, MaxPartIndex}}, unknown.Indexes())
+}
+
+func TestRobot_SummaryEncodings(t *testing.T) {
+	ass := assert.New(t)
+	held := []SecretPart{
+		{Index: 0, Word: "a"}, {Index: 1, Word: "b"}, {Index: 2, Word: "c"},
+		{Index: 5, Word: "f"}, {Index: 9, Word: "j"}, {Index: 10, Word: "k"},
+	}
+	r := NewRobot(3, SecretIntegrity{}, held...)
+
+	for _, encoding := range []string{"list", "ranges", "bitmap", "compact"} {
+		t.Run(encoding, func(t *testing.T) {
+			version, err := ParseSummaryVersion(encoding)
+			ass.NoError(err)
+			summary := r.Summary(version, 0)
+			ass.Equal(int32(3), summary.SenderId)
+			indexes := FromSummaryPb(summary)
+			for i := -1; i <= 12; i++ {
+				_, expected := lo.Find(held, func(item SecretPart) bool { return item.Index == i })
+				ass.Equal(expected, indexes.Holds(SecretPart{Index: i}), "index %d", i)
+			}
+		})
+	}
+
+	ass.Equal(IndexRanges{{0, 2}, {5, 5}, {9, 10}}, NewIndexRanges([]int{10, 0, 2, 1, 9, 5, 2}))
+	ass.Equal(IndexRanges{{0, 2}, {5, 5}, {9, 10}}, r.Indexes(), "read from the bitset")
+	ass.Equal(NewIndexBitmap([]int{0, 1, 2, 5, 9, 10}), IndexBitmap(r.Summary(pb.SummaryVersion_BITMAP, 0).Bitmap))
+
+	contiguous := NewRobot(0, SecretIntegrity{}, lo.Times(200, func(i int) SecretPart { return SecretPart{Index: i + 60} })...)
+	ass.Equal(IndexRanges{{60, 259}}, contiguous.Indexes(), "intervals span the words of the bitset")
+	ass.Equal(pb.SummaryVersion_RANGES, contiguous.Summary(pb.SummaryVersion_COMPACT, 0).Version)
+	scattered := NewRobot(0, SecretIntegrity{}, lo.Times(200, func(i int) SecretPart { return SecretPart{Index: 2 * i} })...)
+	ass.Equal(pb.SummaryVersion_BITMAP, scattered.Summary(pb.SummaryVersion_COMPACT, 0).Version)
+	ass.Equal(pb.SummaryVersion_INDEX_LIST, NewRobot(0, SecretIntegrity{}, SecretPart{Index: 1000}).Summary(pb.SummaryVersion_COMPACT, 0).Version)
+
+	sender := NewRobot(0, SecretIntegrity{}, held...)
+	ass.Equal([]SecretPart{{Index: 5, Word: "f"}, {Index: 9, Word: "j"}},
+		sender.GetWordsToSend(NewIndexRanges([]int{0, 1, 2, 10})))
+	ass.Len(sender.GetWordsToSend(nil), len(held))
+}
+
+func TestBloomFilter_NoFalseNegatives(t *testing.T) {
+	ass := assert.New(t)
+	filter := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.Add(SecretPart{Index: i, Word: "word"})
+	}
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		ass.True(filter.Holds(SecretPart{Index: i, Word: "word"}))
+		if filter.Holds(SecretPart{Index: i + 1000, Word: "word"}) {
+			falsePositives++
+		}
+	}
+	ass.Less(falsePositives, 50, "false positive rate should stay close to the target")
+	ass.InDelta(0.01, filter.FalsePositiveRate(), 0.005)
+
+	ass.Equal(Digest([]SecretPart{{Index: 1, Word: "b"}, {Index: 0, Word: "a"}}),
+		Digest([]SecretPart{{Index: 0, Word: "a"}, {Index: 1, Word: "b"}}))
+	ass.NotEqual(Digest([]SecretPart{{Index: 0, Word: "a"}}), Digest([]SecretPart{{Index: 0, Word: "b"}}))
+}
+
+func TestMerkleTree_DiffFindsMissingLeaves(t *testing.T) {
+	ass := assert.New(t)
+	ass.True(IsValidMerkleLeaves(8))
+	ass.False(IsValidMerkleLeaves(6))
+
+	full := NewRobot(0, SecretIntegrity{}, SecretPart{Index: 0, Word: "a"}, SecretPart{Index: 1, Word: "b"}, SecretPart{Index: 9, Word: "j"})
+	partial := NewRobot(1, SecretIntegrity{}, SecretPart{Index: 0, Word: "a"})
+	full.EnableMerkle(8)
+	partial.EnableMerkle(8)
+
+	inner, leaves := partial.DiffMerkleNodes(full.MerkleNodes([]int{1}))
+	ass.Equal([]int{1}, inner)
+	ass.Empty(leaves)
+
+	// Index 1 and 9 share the same leaf (9 modulo 8)
+	all := lo.RangeFrom(1, 15)
+	_, leaves = partial.DiffMerkleNodes(full.MerkleNodes(all))
+	ass.Equal([]int{9}, leaves)
+	ass.Len(full.LeafParts(leaves), 2)
+
+	// The tree is updated incrementally, whatever the order of the merges
+	partial.MergeSecretPart(SecretPart{Index: 9, Word: "j"})
+	partial.MergeSecretPart(SecretPart{Index: 1, Word: "b"})
+	inner, leaves = partial.DiffMerkleNodes(full.MerkleNodes(all))
+	ass.Empty(inner)
+	ass.Empty(leaves)
+}
+
+// sliceRobot Former slice-backed lookups, kept as a baseline for the benchmarks
+type sliceRobot struct {
+	parts []SecretPart
+}
+
+func (s *sliceRobot) merge(part SecretPart) {
+	if _, ok := lo.Find(s.parts, func(item SecretPart) bool { return item.Index == part.Index }); !ok {
+		s.parts = append(s.parts, part)
+	}
+}
+
+func (s *sliceRobot) isCompleted() bool {
+	indexes := make(map[int]struct{}, len(s.parts))
+	maxIndex := -1
+	for _, p := range s.parts {
+		indexes[p.Index] = struct{}{}
+		maxIndex = max(maxIndex, p.Index)
+	}
+	for i := 0; i <= maxIndex; i++ {
+		if _, ok := indexes[i]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkWords(n int) []SecretPart {
+	return lo.Map(rand.Perm(n), func(index int, _ int) SecretPart {
+		return SecretPart{Index: index, Word: "word"}
+	})
+}
+
+func BenchmarkRobot_MergeSecretPart(b *testing.B) {
+	for _, n := range []int{1_000, 100_000} {
+		parts := benchmarkWords(n)
+		b.Run(fmt.Sprintf("indexed/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				r := &Robot{}
+				for _, part := range parts {
+					r.MergeSecretPart(part)
+				}
+			}
+		})
+		if n > 10_000 {
+			continue // The slice baseline is quadratic
+		}
+		b.Run(fmt.Sprintf("slice/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				r := &sliceRobot{}
+				for _, part := range parts {
+					r.merge(part)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRobot_IsSecretCompleted(b *testing.B) {
+	parts := benchmarkWords(100_000)
+	b.Run("indexed", func(b *testing.B) {
+		r := NewRobot(0, SecretIntegrity{}, parts...)
+		for i := 0; i < b.N; i++ {
+			r.IsSecretCompleted(".")
+		}
+	})
+	b.Run("slice", func(b *testing.B) {
+		r := &sliceRobot{parts: parts}
+		for i := 0; i < b.N; i++ {
+			r.isCompleted()
+		}
+	})
+}
+
+func BenchmarkRobot_GetWordsToSend(b *testing.B) {
+	parts := benchmarkWords(100_000)
+	r := NewRobot(0, SecretIntegrity{}, parts...)
+	receiver := NewIndexBitmap(lo.Map(parts[:50_000], func(item SecretPart, _ int) int {
+		return item.Index
+	}))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.GetWordsToSend(receiver)
+	}
+}
+
+// TestRobot_SnapshotStress Run with -race: snapshots are read while parts are merged concurrently
+func TestRobot_SnapshotStress(t *testing.T) {
+	ass := assert.New(t)
+	const nbrOfRobots, words = 50, 200
+	parts := lo.Map(lo.Range(words), func(index int, _ int) SecretPart {
+		word := "word"
+		if index == words-1 {
+			word = "end."
+		}
+		return SecretPart{Index: index, Word: word}
+	})
+	robots := lo.Times(nbrOfRobots, func(i int) *Robot {
+		return &Robot{ID: ID(i)}
+	})
+
+	var wg sync.WaitGroup
+	for _, r := range robots {
+		// Writers, each merging the whole secret in a random order
+		for w := 0; w < 2; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, part := range lo.Shuffle(slices.Clone(parts)) {
+					r.MergeSecretPart(part)
+				}
+			}()
+		}
+		// Reader, snapshots must be consistent and monotonic
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var last Snapshot
+			for !last.IsSecretCompleted(".") {
+				snapshot := r.Snapshot()
+				ass.GreaterOrEqual(snapshot.Version, last.Version)
+				ass.Equal(snapshot.Known(), len(snapshot.Indexes()))
+				ass.Equal(snapshot.Known(), len(snapshot.Parts))
+				ass.True(snapshot.Missing(".") >= 0)
+				r.GetWordsToSend(IndexList{})
+				r.Summary(0, 0)
+				last = snapshot
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range robots {
+		snapshot := r.Snapshot()
+		ass.True(snapshot.IsSecretCompleted("."))
+		ass.Equal(uint64(words), snapshot.Version)
+		ass.Equal(words, len(snapshot.Words()))
+	}
+}
+
+func TestVersionVector_Compare(t *testing.T) {
+	ass := assert.New(t)
+	a, b := VersionVector{}, VersionVector{}
+	ass.Equal(Equal, a.Compare(b))
+
+	ass.True(a.Add(0, 1))
+	ass.False(a.Add(0, 1))
+	ass.False(a.Add(0, 0), "untracked parts are ignored")
+	ass.Equal(After, a.Compare(b))
+	ass.Equal(Before, b.Compare(a))
+
+	// Out of order parts are kept as dots until the gap is filled
+	b.Add(0, 1)
+	b.Add(0, 3)
+	ass.Equal(VersionEntry{Counter: 1, Dots: []uint64{3}}, b[0])
+	ass.Equal(Before, a.Compare(b))
+	a.Add(1, 1)
+	ass.Equal(Concurrent, a.Compare(b))
+	b.Add(0, 2)
+	ass.Equal(VersionEntry{Counter: 3}, b[0])
+
+	// Same sequences with different counters and dots are still equal
+	c := VersionVector{}
+	c.Add(1, 1)
+	for _, sequence := range []uint64{3, 2, 1} {
+		c.Add(0, sequence)
+	}
+	b.Add(1, 1)
+	ass.Equal(Equal, c.Compare(FromVersionVectorPb(ToVersionVectorPb(b))))
+
+	r := &Robot{}
+	r.MergeSecretPart(SecretPart{Index: 0, Word: "a", Origin: 2, Sequence: 1})
+	r.MergeSecretPart(SecretPart{Index: 5, Word: "b", Origin: 2, Sequence: 3})
+	ass.Equal(VersionVector{2: {Counter: 1, Dots: []uint64{3}}}, r.VersionVector())
+}
+
+func TestRobot_GlobalSnapshotRecordsPartsInTransit(t *testing.T) {
+	ass := assert.New(t)
+	initiator := NewRobot(0, SecretIntegrity{}, SecretPart{Index: 0, Word: "a"})
+	initiator.EnableSnapshots(3)
+	ass.True(initiator.StartSnapshot(1))
+	ass.False(initiator.StartSnapshot(1), "already recorded")
+	ass.Equal(uint64(1), initiator.SnapshotEpoch())
+
+	// A marker on every outgoing link, those that couldn't be sent are retried
+	epoch, markers := initiator.PendingMarkers()
+	ass.Equal(uint64(1), epoch)
+	ass.Len(markers, 4)
+	initiator.RequeueMarkers(epoch, markers[:1])
+	initiator.RequeueMarkers(0, markers[1:2])
+	_, markers = initiator.PendingMarkers()
+	ass.Equal([]PendingMarker{{To: 1, Kind: SummaryLink}}, markers, "markers of an older snapshot are dropped")
+
+	// Parts merged after the recording are not in the local snapshot
+	initiator.MergeSecretPart(SecretPart{Index: 1, Word: "b"})
+	// Messages sent before the peer recorded the snapshot are in transit
+	initiator.ObserveSnapshot(1, UpdateLink, 0, []SecretPart{{Index: 1, Word: "b"}})
+	initiator.ObserveSnapshot(0, UpdateLink, 0, []SecretPart{{Index: 2, Word: "self"}})
+	// The epoch piggybacked on a message acts as the marker and closes the link
+	initiator.ObserveSnapshot(1, UpdateLink, 1, nil)
+	initiator.ObserveSnapshot(1, UpdateLink, 0, []SecretPart{{Index: 2, Word: "late"}})
+	for _, link := range []Link{{1, SummaryLink}, {2, SummaryLink}} {
+		initiator.ObserveSnapshot(link.From, link.Kind, 1, nil)
+	}
+	ass.Empty(initiator.CompletedSnapshots(), "the update link of robot 2 is still open")
+	initiator.ObserveSnapshot(2, UpdateLink, 1, nil)
+
+	completed := initiator.CompletedSnapshots()
+	ass.Len(completed, 1)
+	ass.Equal([]SecretPart{{Index: 0, Word: "a"}}, completed[0].Parts)
+	ass.Equal([]RecordedMessage{{From: 1, Kind: UpdateLink, Parts: []SecretPart{{Index: 1, Word: "b"}}}}, completed[0].InFlight)
+	ass.Empty(initiator.CompletedSnapshots(), "completed snapshots are returned once")
+
+	// A peer receiving a message of a newer epoch records its state first
+	peer := NewRobot(1, SecretIntegrity{}, SecretPart{Index: 1, Word: "b"})
+	peer.EnableSnapshots(3)
+	peer.ObserveSnapshot(0, SummaryLink, 1, nil)
+	ass.Equal(uint64(1), peer.SnapshotEpoch())
+	_, markers = peer.PendingMarkers()
+	ass.Len(markers, 4, "the peer forwards the markers")
+}
+
+func TestRobot_GlobalSnapshotIsConsistentWithSeveralWriters(t *testing.T) {
+	ass := assert.New(t)
+	type message struct {
+		epoch  uint64
+		parts  []SecretPart
+		marker bool
+	}
+	sender, receiver := NewRobot(0, SecretIntegrity{}), NewRobot(1, SecretIntegrity{})
+	sender.EnableSnapshots(2)
+	receiver.EnableSnapshots(2)
+	link := make(chan message, 10_000)
+
+	// Several workers of the sender write to the same link while it records a snapshot
+	var wg sync.WaitGroup
+	for writer := 0; writer < 4; writer++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := writer; index < 4000; index += 4 {
+				part := SecretPart{Index: index, Word: fmt.Sprint(index)}
+				sender.MergeSecretPart(part)
+				sender.Outbox(func() {
+					link <- message{epoch: sender.SnapshotEpoch(), parts: []SecretPart{part}}
+				})
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	sender.StartSnapshot(1)
+	sender.Outbox(func() {
+		epoch, _ := sender.PendingMarkers()
+		link <- message{epoch: epoch, marker: true}
+	})
+	wg.Wait()
+	close(link)
+
+	last := uint64(0)
+	for msg := range link {
+		ass.GreaterOrEqual(msg.epoch, last, "a message never overtakes the marker of a later snapshot")
+		last = msg.epoch
+		receiver.ObserveSnapshot(0, UpdateLink, msg.epoch, msg.parts)
+		if !msg.marker {
+			receiver.MergeSecretPart(msg.parts[0])
+		}
+	}
+	for _, kind := range []LinkKind{SummaryLink, UpdateLink} {
+		sender.ObserveSnapshot(1, kind, 1, nil)
+		receiver.ObserveSnapshot(0, kind, 1, nil)
+	}
+
+	sent, received := sender.CompletedSnapshots(), receiver.CompletedSnapshots()
+	ass.Len(sent, 1)
+	ass.Len(received, 1)
+	recorded := NewIndexList(lo.Map(sent[0].Parts, func(item SecretPart, _ int) int { return item.Index }))
+	for _, part := range received[0].Parts {
+		ass.True(recorded.Holds(part), "part %d was received before the cut, it was sent before it", part.Index)
+	}
+	for _, msg := range received[0].InFlight {
+		for _, part := range msg.Parts {
+			ass.True(recorded.Holds(part), "part %d in transit was sent before the cut", part.Index)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package robot
+
+import (
+	"math/rand"
+	"robots/pkg/errors"
+	"sort"
+	"sync"
+)
+
+type Dissemination string
+
+const (
+	AntiEntropy    Dissemination = "anti-entropy" // Periodic summary exchanges only
+	RumorMongering Dissemination = "rumor"        // Hot rumors only, may leave a residue
+	Hybrid         Dissemination = "hybrid"       // Rumors with periodic anti-entropy as a backup
+)
+
+// ParseDissemination Reads the dissemination setting (anti-entropy by default)
+func ParseDissemination(value string) (Dissemination, error) {
+	switch Dissemination(value) {
+	case "", AntiEntropy:
+		return AntiEntropy, nil
+	case RumorMongering, Hybrid:
+		return Dissemination(value), nil
+	default:
+		return AntiEntropy, errors.ErrUnknownDissemination
+	}
+}
+
+func (d Dissemination) UsesRumors() bool {
+	return d == RumorMongering || d == Hybrid
+}
+
+func (d Dissemination) UsesAntiEntropy() bool {
+	return d == AntiEntropy || d == Hybrid || d == ""
+}
+
+// Rumors Tracks the SIR state of the secret parts known by a robot.
+// A part is susceptible until learned, infective ("hot") while the robot keeps
+// pushing it, and removed once the robot lost interest in spreading it.
+// A robot loses interest after meeting stopAfter peers that already knew
+// the part, or on each such meeting with the loss probability.
+type Rumors struct {
+	mu              sync.Mutex
+	stopAfter       int
+	lossProbability float64
+	rng             *rand.Rand  // Draws the losses, owned by the robot so a seeded run loses the same rumors
+	hot             map[int]int // Index of the part -> number of peers met that already knew it
+	removed         map[int]struct{}
+}
+
+func NewRumors(stopAfter int, lossProbability float64, rng *rand.Rand) *Rumors {
+	return &Rumors{
+		stopAfter:       stopAfter,
+		lossProbability: lossProbability,
+		rng:             rng,
+		hot:             make(map[int]int),
+		removed:         make(map[int]struct{}),
+	}
+}
+
+// Heat A newly learned part becomes a hot rumor, unless the robot already spread it
+func (r *Rumors) Heat(index int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.removed[index]; ok {
+		return
+	}
+	if _, ok := r.hot[index]; !ok {
+		r.hot[index] = 0
+	}
+}
+
+// Hot Returns the indexes of the parts still being spread
+func (r *Rumors) Hot() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	indexes := make([]int, 0, len(r.hot))
+	for index := range r.hot {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// AlreadyKnown Records that a peer already knew the part.
+// Returns true when the robot loses interest and the rumor is removed.
+func (r *Rumors) AlreadyKnown(index int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.hot[index]; !ok {
+		return false
+	}
+	r.hot[index]++
+	stop := r.stopAfter > 0 && r.hot[index] >= r.stopAfter
+	if r.lossProbability > 0 && r.rng.Float64() < r.lossProbability {
+		stop = true
+	}
+	if stop {
+		delete(r.hot, index)
+		r.removed[index] = struct{}{}
+	}
+	return stop
+}
+
+// Residue Reports how far the dissemination went at the end of a run.
+// In rumor mongering some robots may never get a part: this is the residue.
+// With a coded scheme, a robot holding the threshold of shares or fragments
+// rebuilds the secret: the parts it lacks are not part of the residue.
+type Residue struct {
+	Parts           int         // Number of parts of the secret: words, shares or fragments
+	RobotsMissing   int         // Robots that can't rebuild the secret
+	MissingByIndex  map[int]int // Index of the part -> number of robots that never got it and can't rebuild the secret
+	AverageResidue  float64     // Average share of robots that never got a given part and can't rebuild the secret
+	CompletedRobots int
+}
+
+// ComputeResidue Compares each robot's parts with the parts of the secret
+func ComputeResidue(robots []*Robot, parts int) Residue {
+	residue := Residue{Parts: parts, MissingByIndex: make(map[int]int)}
+	for _, r := range robots {
+		snapshot := r.Snapshot()
+		coded := snapshot.integrity.Threshold > 0
+		if coded && snapshot.HasEnoughParts("") || !coded && snapshot.Known() == parts {
+			residue.CompletedRobots++
+			continue
+		}
+		residue.RobotsMissing++
+		held := make(map[int]struct{})
+		for _, index := range snapshot.Indexes() {
+			held[int(index)] = struct{}{}
+		}
+		for index := 0; index < parts; index++ {
+			if _, ok := held[index]; !ok {
+				residue.MissingByIndex[index]++
+			}
+		}
+	}
+	if parts > 0 && len(robots) > 0 {
+		total := 0
+		for _, count := range residue.MissingByIndex {
+			total += count
+		}
+		residue.AverageResidue = float64(total) / float64(parts*len(robots))
+	}
+	return residue
+}
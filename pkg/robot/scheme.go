@@ -0,0 +1,132 @@
+package robot
+
+import (
+	"crypto/rand"
+	"robots/pkg/codes"
+	"robots/pkg/errors"
+)
+
+type Scheme string
+
+const (
+	WordsScheme       Scheme = "words"        // Each part is a plaintext word of the secret
+	ShamirScheme      Scheme = "shamir"       // Each part is a Shamir share, any threshold shares reveal the secret
+	ReedSolomonScheme Scheme = "reed-solomon" // Each part is an erasure-coded fragment, any k fragments rebuild the secret
+)
+
+// ParseScheme Reads the secret scheme setting (words by default)
+func ParseScheme(value string) (Scheme, error) {
+	switch Scheme(value) {
+	case "", WordsScheme:
+		return WordsScheme, nil
+	case ShamirScheme, ReedSolomonScheme:
+		return Scheme(value), nil
+	default:
+		return WordsScheme, errors.ErrUnknownSecretScheme
+	}
+}
+
+// Coded The parts are shares or fragments dealt one per robot in turn, not words
+func (s Scheme) Coded() bool {
+	return s == ShamirScheme || s == ReedSolomonScheme
+}
+
+// Shares Number of Shamir shares dealt, one per robot unless configured
+func (s SecretManager) Shares() int {
+	if s.Config.ShamirShares > 0 {
+		return s.Config.ShamirShares
+	}
+	return s.Config.NbrOfRobots
+}
+
+// Fragments Number of Reed–Solomon fragments dealt, one per robot unless configured
+func (s SecretManager) Fragments() int {
+	if s.Config.ErasureFragments > 0 {
+		return s.Config.ErasureFragments
+	}
+	return s.Config.NbrOfRobots
+}
+
+// Threshold Number of distinct parts needed to rebuild the secret with a coded scheme, 0 for words
+func (s SecretManager) Threshold() int {
+	switch scheme, _ := ParseScheme(s.Config.SecretScheme); scheme {
+	case ShamirScheme:
+		return s.Config.ShamirThreshold
+	case ReedSolomonScheme:
+		return s.Config.ErasureDataFragments
+	default:
+		return 0
+	}
+}
+
+// NumberOfParts Number of parts gossiped: the words, the shares or the fragments
+func (s SecretManager) NumberOfParts(words []string) int {
+	switch scheme, _ := ParseScheme(s.Config.SecretScheme); scheme {
+	case ShamirScheme:
+		return s.Shares()
+	case ReedSolomonScheme:
+		return s.Fragments()
+	default:
+		return len(words)
+	}
+}
+
+// SplitParts Turns the words into the parts to deal, without origin.
+// With the Shamir scheme, the whole secret is split into shares whose
+// random coefficients never leave this function.
+// With the Reed–Solomon scheme, the whole secret is encoded into data and parity fragments.
+func (s SecretManager) SplitParts(words []string) []SecretPart {
+	secret := []byte(s.Tokenizer().Join(words))
+	var parts []SecretPart
+	// The parameters are validated when the configuration is loaded
+	switch scheme, _ := ParseScheme(s.Config.SecretScheme); scheme {
+	case ShamirScheme:
+		shares, _ := codes.Split(secret, s.Shares(), s.Config.ShamirThreshold, rand.Reader)
+		for _, share := range shares {
+			parts = append(parts, SecretPart{Index: int(share.X) - 1, Data: share.Y})
+		}
+	case ReedSolomonScheme:
+		fragments, _ := codes.Encode(secret, s.Fragments(), s.Config.ErasureDataFragments)
+		for _, fragment := range fragments {
+			parts = append(parts, SecretPart{Index: fragment.Index, Data: fragment.Data})
+		}
+	default:
+		plain := isWords(s.Tokenizer())
+		for index, word := range words {
+			if plain {
+				parts = append(parts, SecretPart{Index: index, Word: word})
+			} else {
+				parts = append(parts, SecretPart{Index: index, Data: []byte(word)})
+			}
+		}
+	}
+	return parts
+}
+
+// rebuild Combines the first shares, or decodes the first fragments, held up to the threshold.
+// Below the threshold, no secret can be built.
+func rebuild(scheme Scheme, parts []SecretPart, threshold int) string {
+	if threshold <= 0 || len(parts) < threshold {
+		return ""
+	}
+	var secret []byte
+	var err error
+	switch scheme {
+	case ShamirScheme:
+		shares := make([]codes.Share, threshold)
+		for i, part := range parts[:threshold] {
+			shares[i] = codes.Share{X: byte(part.Index + 1), Y: part.Data}
+		}
+		secret, err = codes.Combine(shares)
+	case ReedSolomonScheme:
+		fragments := make([]codes.Fragment, threshold)
+		for i, part := range parts[:threshold] {
+			fragments[i] = codes.Fragment{Index: part.Index, Data: part.Data}
+		}
+		secret, err = codes.Decode(fragments, threshold)
+	}
+	if err != nil {
+		return ""
+	}
+	return string(secret)
+}
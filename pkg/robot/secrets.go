@@ -0,0 +1,84 @@
+package robot
+
+import (
+	"robots/pkg/errors"
+	"slices"
+	"strings"
+)
+
+// SecretID Name of a secret disseminated in the cluster, empty for the first secret of the run
+type SecretID string
+
+// Secrets State of every robot for each secret disseminated at once, indexed by robot ID.
+// Only the summaries and the updates of anti-entropy tell which secret they are about:
+// rumors, Merkle reconciliation, Safra's termination and the elections only run for the first secret,
+// and global snapshots and their collector only record it.
+type Secrets map[SecretID][]*Robot
+
+// IDs Returns the secrets in a stable order, the first secret of the run first
+func (s Secrets) IDs() []SecretID {
+	ids := make([]SecretID, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Robot Returns the state of a robot for a secret
+func (s Secrets) Robot(secret SecretID, id ID) (*Robot, bool) {
+	robots, ok := s[secret]
+	if !ok || id < 0 || int(id) >= len(robots) {
+		return nil, false
+	}
+	return robots[id], true
+}
+
+// All Returns the state of every robot for every secret
+func (s Secrets) All() []*Robot {
+	var all []*Robot
+	for _, id := range s.IDs() {
+		all = append(all, s[id]...)
+	}
+	return all
+}
+
+// SecretFile Secret read from a file, disseminated along the first secret of the run
+type SecretFile struct {
+	ID   SecretID
+	Path string
+}
+
+// ParseSecretFiles Reads the secrets setting, name=path pairs separated by commas
+func ParseSecretFiles(value string) ([]SecretFile, error) {
+	var files []SecretFile
+	seen := make(map[SecretID]bool)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, path, ok := strings.Cut(pair, "=")
+		id := SecretID(strings.TrimSpace(name))
+		if !ok || id == "" || strings.TrimSpace(path) == "" || seen[id] {
+			return nil, errors.ErrInvalidSecrets
+		}
+		seen[id] = true
+		files = append(files, SecretFile{ID: id, Path: strings.TrimSpace(path)})
+	}
+	return files, nil
+}
+
+// CreateSecret Creates the state of the robots for another secret disseminated at once.
+// The robots keep the channels of their hosts: every secret shares the same channels
+// and buffers, and the messages tell which secret they are about.
+// They also share the Lamport clocks of their hosts, so every message of a robot is stamped by one clock.
+func (s SecretManager) CreateSecret(id SecretID, words []string, hosts []*Robot) []*Robot {
+	robots := s.CreateRobots(words)
+	for i, r := range robots {
+		host := hosts[i]
+		r.Secret, r.Clock = id, host.Clock
+		r.GossipSummary, r.GossipUpdate, r.GossipRumor = host.GossipSummary, host.GossipUpdate, host.GossipRumor
+		r.RumorFeedback, r.GossipMerkle, r.TerminationToken = host.RumorFeedback, host.GossipMerkle, host.TerminationToken
+	}
+	return robots
+}
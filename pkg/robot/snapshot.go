@@ -0,0 +1,139 @@
+package robot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Snapshot Immutable copy of the state of a robot, taken under its read lock.
+// Workers and handlers read a robot only through snapshots, so they never
+// race with MergeSecretPart and always see a consistent state.
+//
+// Taking a snapshot is O(1): the arrival log is append-only, so the snapshot
+// shares its prefix instead of copying it, and the parts ordered by index
+// are only rebuilt from the index when parts were merged since the last
+// snapshot. Parts must never be modified.
+type Snapshot struct {
+	ID            ID
+	Parts         []SecretPart // Parts in arrival order, shared with the robot
+	LastUpdatedAt time.Time
+	Version       uint64 // Number of parts merged when the snapshot was taken
+	known         int
+	maxIndex      int
+	lastWord      string
+	integrity     SecretIntegrity
+	ordered       *orderedView
+}
+
+// Snapshot Returns a consistent view over the robot's state
+func (r *Robot) Snapshot() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snapshot := Snapshot{
+		ID:            r.ID,
+		Parts:         r.arrivals[:len(r.arrivals):len(r.arrivals)],
+		LastUpdatedAt: r.LastUpdatedAt,
+		Version:       r.version,
+		maxIndex:      -1,
+		integrity:     r.Integrity,
+		ordered:       r.index.ordered(),
+	}
+	if r.index != nil {
+		snapshot.known, snapshot.maxIndex = r.index.count, r.index.maxIndex
+	}
+	if snapshot.maxIndex >= 0 {
+		snapshot.lastWord = r.index.parts[r.index.maxIndex].Word
+	}
+	return snapshot
+}
+
+// Known Returns the number of distinct indexes held
+func (s Snapshot) Known() int {
+	return s.known
+}
+
+// IsSecretCompleted All indexes from 0 to the highest one are held and the last word ends the secret.
+// With the integrity of the secret, every word (or enough shares or fragments) is held and the secret matches its hash.
+func (s Snapshot) IsSecretCompleted(endOfSecret string) bool {
+	if !s.HasEnoughParts(endOfSecret) {
+		return false
+	}
+	if !s.integrity.Known() {
+		return true
+	}
+	_, _, ok := s.Verify()
+	return ok
+}
+
+// Missing Returns an estimate of how many words are still lacking.
+// Gaps below the highest known index are counted exactly, and an unknown tail
+// (last word without the end-of-secret marker) counts as one missing word.
+// With the integrity of the secret, the count is exact.
+// With a coded scheme, it counts the shares or fragments lacking to reach the threshold.
+func (s Snapshot) Missing(endOfSecret string) int {
+	if s.integrity.Threshold > 0 {
+		return max(0, s.integrity.Threshold-s.known)
+	}
+	if s.integrity.Known() {
+		return max(0, s.integrity.WordCount-s.known)
+	}
+	if s.known == 0 {
+		return 1
+	}
+	missing := s.maxIndex + 1 - s.known
+	if !strings.HasSuffix(s.lastWord, endOfSecret) {
+		missing++
+	}
+	return missing
+}
+
+// Ordered Returns the distinct parts ordered by index, taken from the robot's index.
+// The slice is shared and must never be modified.
+func (s Snapshot) Ordered() []SecretPart {
+	return s.view().parts
+}
+
+// Indexes Returns the distinct indexes held, in increasing order.
+// The slice is shared and must never be modified.
+func (s Snapshot) Indexes() []int64 {
+	return s.view().indexes
+}
+
+// view The zero snapshot has no part
+func (s Snapshot) view() *orderedView {
+	if s.ordered == nil {
+		return emptyView
+	}
+	return s.ordered
+}
+
+// Words Returns the words ordered by index
+func (s Snapshot) Words() []string {
+	return lo.Map(s.Ordered(), func(item SecretPart, _ int) string {
+		return item.Word
+	})
+}
+
+// Tokens Returns the tokens ordered by index, whether held as words or as data
+func (s Snapshot) Tokens() []string {
+	return lo.Map(s.Ordered(), func(item SecretPart, _ int) string {
+		return item.Word + string(item.Data)
+	})
+}
+
+// BuildSecret Joins the words (or the tokens, reproducing the original bytes),
+// or rebuilds the secret from the shares or fragments.
+// Below the threshold, no secret can be built with a coded scheme.
+func (s Snapshot) BuildSecret() string {
+	if s.integrity.Threshold > 0 {
+		return rebuild(s.integrity.Scheme, s.Ordered(), s.integrity.Threshold)
+	}
+	return s.integrity.tokenizer().Join(s.Tokens())
+}
+
+// Digest Hash of the parts held when the snapshot was taken
+func (s Snapshot) Digest() []byte {
+	return s.view().Digest()
+}
@@ -0,0 +1,171 @@
+package robot
+
+import (
+	"robots/pkg/errors"
+	pb "robots/proto"
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+var summaryVersions = map[string]pb.SummaryVersion{
+	"":        pb.SummaryVersion_INDEX_LIST,
+	"list":    pb.SummaryVersion_INDEX_LIST,
+	"ranges":  pb.SummaryVersion_RANGES,
+	"bitmap":  pb.SummaryVersion_BITMAP,
+	"compact": pb.SummaryVersion_COMPACT,
+	"bloom":   pb.SummaryVersion_BLOOM,
+}
+
+// DefaultBloomFalsePositiveRate Used when no valid rate is given for a bloom summary
+const DefaultBloomFalsePositiveRate = 0.01
+
+// ParseSummaryVersion Reads the summary encoding setting (plain index list by default)
+func ParseSummaryVersion(value string) (pb.SummaryVersion, error) {
+	version, ok := summaryVersions[value]
+	if !ok {
+		return pb.SummaryVersion_INDEX_LIST, errors.ErrUnknownSummaryEncoding
+	}
+	return version, nil
+}
+
+// PartSet Read-only view over the parts held by a robot, whatever the summary encoding.
+// Exact encodings only look at the index, probabilistic ones may give false positives.
+type PartSet interface {
+	Holds(part SecretPart) bool
+}
+
+// IndexSet Exact set of indexes held by a robot
+type IndexSet interface {
+	PartSet
+	Contains(index int) bool
+}
+
+// IndexList Plain set of indexes
+type IndexList map[int]struct{}
+
+func NewIndexList(indexes []int) IndexList {
+	list := make(IndexList, len(indexes))
+	for _, index := range indexes {
+		list[index] = struct{}{}
+	}
+	return list
+}
+
+func (l IndexList) Contains(index int) bool {
+	_, ok := l[index]
+	return ok
+}
+
+func (l IndexList) Holds(part SecretPart) bool {
+	return l.Contains(part.Index)
+}
+
+// IndexRange Interval of consecutive indexes, both bounds included
+type IndexRange struct {
+	Start int
+	End   int
+}
+
+// IndexRanges Sorted and disjoint intervals of indexes
+type IndexRanges []IndexRange
+
+// NewIndexRanges Compacts indexes into sorted intervals (duplicates are ignored)
+func NewIndexRanges(indexes []int) IndexRanges {
+	sorted := lo.Uniq(indexes)
+	sort.Ints(sorted)
+	var ranges IndexRanges
+	for _, index := range sorted {
+		last := len(ranges) - 1
+		if last >= 0 && ranges[last].End+1 == index {
+			ranges[last].End = index
+			continue
+		}
+		ranges = append(ranges, IndexRange{Start: index, End: index})
+	}
+	return ranges
+}
+
+// Contains Binary search over the intervals
+func (r IndexRanges) Contains(index int) bool {
+	i := sort.Search(len(r), func(i int) bool {
+		return r[i].End >= index
+	})
+	return i < len(r) && r[i].Start <= index
+}
+
+func (r IndexRanges) Holds(part SecretPart) bool {
+	return r.Contains(part.Index)
+}
+
+// IndexBitmap One bit per index, the lowest bit of the first byte is index 0
+type IndexBitmap []byte
+
+func NewIndexBitmap(indexes []int) IndexBitmap {
+	if len(indexes) == 0 {
+		return nil
+	}
+	bitmap := make(IndexBitmap, lo.Max(indexes)/8+1)
+	for _, index := range indexes {
+		if index >= 0 {
+			bitmap[index/8] |= 1 << (index % 8)
+		}
+	}
+	return bitmap
+}
+
+func (b IndexBitmap) Contains(index int) bool {
+	if index < 0 || index/8 >= len(b) {
+		return false
+	}
+	return b[index/8]&(1<<(index%8)) != 0
+}
+
+func (b IndexBitmap) Holds(part SecretPart) bool {
+	return b.Contains(part.Index)
+}
+
+// Summary Encodes the indexes of the robot in the requested version.
+// Ranges and bitmaps are read straight from the robot's index, and a compact
+// summary takes whichever exact encoding is the smallest for the current state.
+// The false positive rate only applies to bloom summaries, which also carry
+// a digest of the robot's parts so identical states are not reconciled.
+func (r *Robot) Summary(version pb.SummaryVersion, falsePositiveRate float64) *pb.GossipSummary {
+	summary := &pb.GossipSummary{SenderId: int32(r.ID), Version: version, Vector: ToVersionVectorPb(r.VersionVector()), SnapshotEpoch: r.SnapshotEpoch(), SecretId: string(r.Secret)}
+	if version != pb.SummaryVersion_BLOOM {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		r.index.encode(summary, version)
+		return summary
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = DefaultBloomFalsePositiveRate
+	}
+	snapshot := r.Snapshot()
+	parts := snapshot.Ordered()
+	filter := NewBloomFilter(len(parts), falsePositiveRate)
+	for _, part := range parts {
+		filter.Add(part)
+	}
+	summary.Bloom = &pb.BloomFilter{Bits: filter.Bits, Hashes: filter.Hashes, Items: filter.Items}
+	summary.Digest = snapshot.Digest()
+	return summary
+}
+
+// FromSummaryPb Decodes the indexes of a summary without expanding compact encodings
+func FromSummaryPb(summary *pb.GossipSummary) PartSet {
+	switch summary.Version {
+	case pb.SummaryVersion_RANGES:
+		return IndexRanges(lo.Map(summary.Ranges, func(item *pb.IndexRange, _ int) IndexRange {
+			return IndexRange{Start: int(item.Start), End: int(item.End)}
+		}))
+	case pb.SummaryVersion_BITMAP:
+		return IndexBitmap(summary.Bitmap)
+	case pb.SummaryVersion_BLOOM:
+		return &BloomFilter{Bits: summary.Bloom.GetBits(), Hashes: summary.Bloom.GetHashes(), Items: summary.Bloom.GetItems()}
+	default:
+		return NewIndexList(lo.Map(summary.Indexes, func(item int64, _ int) int {
+			return int(item)
+		}))
+	}
+}
@@ -0,0 +1,115 @@
+package robot
+
+import (
+	"robots/pkg/errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tokenizer Splits the secret into the tokens dealt as parts, and joins them back.
+// Tokens are Go strings holding raw bytes, they may not be valid UTF-8.
+type Tokenizer interface {
+	Split(secret string) []string
+	Join(tokens []string) string
+}
+
+// WordTokenizer Words separated by whitespace, joined by a single space: the original whitespace is lost
+type WordTokenizer struct{}
+
+func (WordTokenizer) Split(secret string) []string {
+	return strings.Fields(secret)
+}
+
+func (WordTokenizer) Join(tokens []string) string {
+	return strings.Join(tokens, " ")
+}
+
+// RuneTokenizer One token per UTF-8 character, an invalid byte being a token of its own
+type RuneTokenizer struct{}
+
+func (RuneTokenizer) Split(secret string) []string {
+	var tokens []string
+	for len(secret) > 0 {
+		_, size := utf8.DecodeRuneInString(secret)
+		tokens = append(tokens, secret[:size])
+		secret = secret[size:]
+	}
+	return tokens
+}
+
+func (RuneTokenizer) Join(tokens []string) string {
+	return strings.Join(tokens, "")
+}
+
+// ByteTokenizer One token per byte, for binary content
+type ByteTokenizer struct{}
+
+func (ByteTokenizer) Split(secret string) []string {
+	return ChunkTokenizer{Size: 1}.Split(secret)
+}
+
+func (ByteTokenizer) Join(tokens []string) string {
+	return strings.Join(tokens, "")
+}
+
+// LineTokenizer One token per line, each keeping its line feed
+type LineTokenizer struct{}
+
+func (LineTokenizer) Split(secret string) []string {
+	tokens := strings.SplitAfter(secret, "\n")
+	if tokens[len(tokens)-1] == "" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+func (LineTokenizer) Join(tokens []string) string {
+	return strings.Join(tokens, "")
+}
+
+// ChunkTokenizer Fixed-size chunks of bytes, the last one may be shorter
+type ChunkTokenizer struct {
+	Size int
+}
+
+func (c ChunkTokenizer) Split(secret string) []string {
+	var tokens []string
+	for len(secret) > 0 {
+		size := min(max(1, c.Size), len(secret))
+		tokens = append(tokens, secret[:size])
+		secret = secret[size:]
+	}
+	return tokens
+}
+
+func (ChunkTokenizer) Join(tokens []string) string {
+	return strings.Join(tokens, "")
+}
+
+// ParseTokenizer Reads the tokenizer setting (words by default)
+func ParseTokenizer(value string, chunkSize int) (Tokenizer, error) {
+	switch value {
+	case "", "words":
+		return WordTokenizer{}, nil
+	case "runes":
+		return RuneTokenizer{}, nil
+	case "bytes":
+		return ByteTokenizer{}, nil
+	case "lines":
+		return LineTokenizer{}, nil
+	case "chunks":
+		if chunkSize < 1 {
+			return WordTokenizer{}, errors.ErrInvalidChunkSize
+		}
+		return ChunkTokenizer{Size: chunkSize}, nil
+	default:
+		return WordTokenizer{}, errors.ErrUnknownTokenizer
+	}
+}
+
+// isWords Words are gossiped as plain words, so that the end-of-secret marker still applies.
+// Other tokens are raw bytes, gossiped as data.
+func isWords(tokenizer Tokenizer) bool {
+	_, ok := tokenizer.(WordTokenizer)
+	return ok || tokenizer == nil
+}
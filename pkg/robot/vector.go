@@ -0,0 +1,129 @@
+package robot
+
+import (
+	pb "robots/proto"
+	"slices"
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+// Ordering Causal relation between two version vectors
+type Ordering string
+
+const (
+	Equal      Ordering = "equal"
+	Before     Ordering = "before"     // Every part of the first vector is known by the second
+	After      Ordering = "after"      // Every part of the second vector is known by the first
+	Concurrent Ordering = "concurrent" // Each vector knows parts the other one lacks
+)
+
+// VersionEntry Parts of an origin robot that have been integrated:
+// every sequence up to Counter, plus the sequences received out of order.
+type VersionEntry struct {
+	Counter uint64
+	Dots    []uint64 // Sorted, all greater than Counter + 1
+}
+
+func (e VersionEntry) contains(sequence uint64) bool {
+	if sequence <= e.Counter {
+		return true
+	}
+	_, found := slices.BinarySearch(e.Dots, sequence)
+	return found
+}
+
+// covers Every sequence of the other entry is contained in this one
+func (e VersionEntry) covers(other VersionEntry) bool {
+	for sequence := e.Counter + 1; sequence <= other.Counter; sequence++ {
+		if !e.contains(sequence) {
+			return false
+		}
+	}
+	return lo.EveryBy(other.Dots, e.contains)
+}
+
+// VersionVector For each origin robot, the parts of that origin a robot has integrated.
+// Parts are identified by (origin, sequence), so comparing two vectors tells
+// whether a robot knows everything another one knows, without listing indexes.
+// Parts without sequence (zero) are not tracked.
+type VersionVector map[ID]VersionEntry
+
+// Add Records a part, returns false when it was already known
+func (v VersionVector) Add(origin ID, sequence uint64) bool {
+	entry := v[origin]
+	if sequence == 0 || entry.contains(sequence) {
+		return false
+	}
+	entry.Dots = slices.Insert(slices.Clone(entry.Dots), sort.Search(len(entry.Dots), func(i int) bool {
+		return entry.Dots[i] > sequence
+	}), sequence)
+	// Compact the dots that now follow the counter
+	for len(entry.Dots) > 0 && entry.Dots[0] == entry.Counter+1 {
+		entry.Counter++
+		entry.Dots = entry.Dots[1:]
+	}
+	if len(entry.Dots) == 0 {
+		entry.Dots = nil
+	}
+	v[origin] = entry
+	return true
+}
+
+func (v VersionVector) Copy() VersionVector {
+	vector := make(VersionVector, len(v))
+	for origin, entry := range v {
+		vector[origin] = VersionEntry{Counter: entry.Counter, Dots: slices.Clone(entry.Dots)}
+	}
+	return vector
+}
+
+// Covers Every part known by the other vector is known by this one
+func (v VersionVector) Covers(other VersionVector) bool {
+	for origin, entry := range other {
+		if !v[origin].covers(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare Returns the causal relation of this vector with the other one
+func (v VersionVector) Compare(other VersionVector) Ordering {
+	covers, covered := v.Covers(other), other.Covers(v)
+	switch {
+	case covers && covered:
+		return Equal
+	case covers:
+		return After
+	case covered:
+		return Before
+	default:
+		return Concurrent
+	}
+}
+
+// VersionVector Returns a copy of the robot's version vector
+func (r *Robot) VersionVector() VersionVector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.vector.Copy()
+}
+
+func ToVersionVectorPb(vector VersionVector) []*pb.VersionEntry {
+	entries := lo.MapToSlice(vector, func(origin ID, entry VersionEntry) *pb.VersionEntry {
+		return &pb.VersionEntry{OriginId: int32(origin), Counter: entry.Counter, Dots: entry.Dots}
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].OriginId < entries[j].OriginId
+	})
+	return entries
+}
+
+func FromVersionVectorPb(entries []*pb.VersionEntry) VersionVector {
+	vector := make(VersionVector, len(entries))
+	for _, entry := range entries {
+		vector[ID(entry.OriginId)] = VersionEntry{Counter: entry.Counter, Dots: slices.Clone(entry.Dots)}
+	}
+	return vector
+}
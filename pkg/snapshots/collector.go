@@ -0,0 +1,132 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"robots/pkg/robot"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Global Consistent cut of the whole system, assembled from the local
+// snapshots of every robot (Chandy-Lamport)
+type Global struct {
+	ID         uint64                `json:"id"`
+	TakenAt    time.Time             `json:"taken_at"`
+	Robots     []robot.LocalSnapshot `json:"robots"`
+	Violations []string              `json:"violations,omitempty"`
+	Valid      bool                  `json:"valid"`
+}
+
+// Collector Assembles local snapshots into global ones.
+// Only one global snapshot is in progress at a time.
+type Collector struct {
+	mu          sync.Mutex
+	nbrOfRobots int
+	words       int
+	lastID      uint64
+	startedAt   time.Time
+	inProgress  bool
+	locals      map[robot.ID]robot.LocalSnapshot
+	requests    chan struct{}
+}
+
+func NewCollector(nbrOfRobots, words int) *Collector {
+	return &Collector{nbrOfRobots: nbrOfRobots, words: words, requests: make(chan struct{}, 1)}
+}
+
+// Request Asks for a global snapshot, requests made while one is pending are merged
+func (c *Collector) Request() {
+	select {
+	case c.requests <- struct{}{}:
+	default:
+	}
+}
+
+// Requests Channel of the snapshot requests, read by the initiator
+func (c *Collector) Requests() <-chan struct{} {
+	return c.requests
+}
+
+// Begin Returns the ID of a new global snapshot, or false if one is still in progress
+func (c *Collector) Begin() (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inProgress {
+		return 0, false
+	}
+	c.lastID++
+	c.inProgress = true
+	c.startedAt = time.Now().UTC()
+	c.locals = make(map[robot.ID]robot.LocalSnapshot, c.nbrOfRobots)
+	return c.lastID, true
+}
+
+// Add Collects a local snapshot and returns the global snapshot once every robot recorded its own.
+// Local snapshots of an abandoned global snapshot are ignored.
+func (c *Collector) Add(local robot.LocalSnapshot) (*Global, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.inProgress || local.ID != c.lastID {
+		return nil, false
+	}
+	c.locals[local.RobotID] = local
+	if len(c.locals) < c.nbrOfRobots {
+		return nil, false
+	}
+	global := &Global{ID: c.lastID, TakenAt: c.startedAt}
+	for _, local := range c.locals {
+		global.Robots = append(global.Robots, local)
+	}
+	sort.Slice(global.Robots, func(i, j int) bool { return global.Robots[i].RobotID < global.Robots[j].RobotID })
+	global.Violations = Check(global.Robots, c.words)
+	global.Valid = len(global.Violations) == 0
+	c.inProgress = false
+	c.locals = nil
+	return global, true
+}
+
+// Check Verifies the invariants of the secret on a consistent cut:
+// - no index maps to two different words (or shares), among robots and messages in transit
+// - every part of the secret is held by a robot or in transit
+func Check(locals []robot.LocalSnapshot, words int) []string {
+	var violations []string
+	seen := make(map[int]string)
+	check := func(part robot.SecretPart, where string) {
+		word, ok := seen[part.Index]
+		if !ok {
+			seen[part.Index] = part.Word + string(part.Data)
+			return
+		}
+		if word != part.Word+string(part.Data) {
+			violations = append(violations, fmt.Sprintf("index %d maps to %q and %q (%s)", part.Index, word, part.Word+string(part.Data), where))
+		}
+	}
+	for _, local := range locals {
+		for _, part := range local.Parts {
+			check(part, fmt.Sprintf("robot %d", local.RobotID))
+		}
+		for _, message := range local.InFlight {
+			for _, part := range message.Parts {
+				check(part, fmt.Sprintf("%s from robot %d to robot %d", message.Kind, message.From, local.RobotID))
+			}
+		}
+	}
+	for index := 0; index < words; index++ {
+		if _, ok := seen[index]; !ok {
+			violations = append(violations, fmt.Sprintf("index %d is lost", index))
+		}
+	}
+	return violations
+}
+
+// Write Saves the global snapshot as JSON
+func Write(path string, global *Global) error {
+	data, err := json.MarshalIndent(global, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
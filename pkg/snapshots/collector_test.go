@@ -0,0 +1,35 @@
+package snapshots
+
+import (
+	"robots/pkg/robot"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector_AssemblesAndChecksGlobalSnapshot(t *testing.T) {
+	ass := assert.New(t)
+	collector := NewCollector(2, 3)
+	id, ok := collector.Begin()
+	ass.True(ok)
+	_, ok = collector.Begin()
+	ass.False(ok, "only one global snapshot at a time")
+
+	_, ok = collector.Add(robot.LocalSnapshot{ID: id, RobotID: 1, Parts: []robot.SecretPart{{Index: 1, Word: "b"}}})
+	ass.False(ok)
+	global, ok := collector.Add(robot.LocalSnapshot{
+		ID:       id,
+		RobotID:  0,
+		Parts:    []robot.SecretPart{{Index: 0, Word: "a"}},
+		InFlight: []robot.RecordedMessage{{From: 1, Kind: robot.UpdateLink, Parts: []robot.SecretPart{{Index: 2, Word: "c"}}}},
+	})
+	ass.True(ok)
+	ass.True(global.Valid)
+	ass.Equal(robot.ID(0), global.Robots[0].RobotID)
+
+	violations := Check([]robot.LocalSnapshot{
+		{RobotID: 0, Parts: []robot.SecretPart{{Index: 0, Word: "a"}}},
+		{RobotID: 1, Parts: []robot.SecretPart{{Index: 0, Word: "z"}}},
+	}, 2)
+	ass.Len(violations, 2, "a conflicting word and a lost index")
+}
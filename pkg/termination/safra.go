@@ -0,0 +1,87 @@
+package termination
+
+import (
+	"robots/pkg/errors"
+	"sync"
+)
+
+type Detection string
+
+const (
+	QuietPeriod Detection = "quiet-period" // Local heuristic: complete and nothing received for a while
+	Safra       Detection = "safra"        // Dijkstra-Safra token ring, alongside the heuristic
+)
+
+// ParseDetection Reads the termination detection setting (quiet period by default)
+func ParseDetection(value string) (Detection, error) {
+	switch Detection(value) {
+	case "", QuietPeriod:
+		return QuietPeriod, nil
+	case Safra:
+		return Safra, nil
+	default:
+		return QuietPeriod, errors.ErrUnknownTerminationDetection
+	}
+}
+
+// SafraState Local state of a robot for the Dijkstra-Safra termination detection.
+// Basic messages are the ones carrying secret parts. A robot is passive once it
+// holds the whole secret, which never changes afterwards.
+//
+// The counter is the number of basic messages sent minus the number received.
+// A robot turns black when it receives a basic message: the message may have
+// been sent after the token visited the sender, so the wave can't conclude.
+// The zero value is a white robot with no message, ready to use.
+type SafraState struct {
+	mu      sync.Mutex
+	counter int64
+	black   bool
+}
+
+// Sent Records a basic message about to be put on a channel.
+// It is counted before the send, so it can never be in transit uncounted.
+func (s *SafraState) Sent() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter++
+}
+
+// Dropped Cancels Sent when the message couldn't be put on the channel
+func (s *SafraState) Dropped() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter--
+}
+
+// Received Records a basic message taken from a channel
+func (s *SafraState) Received() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter--
+	s.black = true
+}
+
+// Whiten Called by the initiator when it starts a new wave
+func (s *SafraState) Whiten() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.black = false
+}
+
+// Forward Adds the robot's counter and color to the token, then whitens the robot
+func (s *SafraState) Forward(count int64, black bool) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count += s.counter
+	black = black || s.black
+	s.black = false
+	return count, black
+}
+
+// Terminated Evaluated by the initiator when the token is back: every robot was passive
+// when the token visited it, none received a basic message meanwhile and no
+// basic message is in transit.
+func (s *SafraState) Terminated(count int64, black bool) bool {
+	count, black = s.Forward(count, black)
+	return !black && count == 0
+}
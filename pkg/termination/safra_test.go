@@ -0,0 +1,43 @@
+package termination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafra_MessageInTransit(t *testing.T) {
+	ass := assert.New(t)
+	var initiator, other SafraState
+
+	// Robot 0 sent a basic message that robot 1 didn't receive yet
+	initiator.Sent()
+	initiator.Whiten()
+	count, black := other.Forward(0, false)
+	ass.False(initiator.Terminated(count, black))
+
+	// The message is received after the token visited robot 1: robot 1 turns black
+	other.Received()
+	initiator.Whiten()
+	count, black = other.Forward(0, false)
+	ass.True(black)
+	ass.False(initiator.Terminated(count, black))
+
+	// A new wave with no activity concludes
+	initiator.Whiten()
+	count, black = other.Forward(0, false)
+	ass.Equal(int64(-1), count)
+	ass.True(initiator.Terminated(count, black))
+}
+
+func TestParseDetection(t *testing.T) {
+	ass := assert.New(t)
+	detection, err := ParseDetection("")
+	ass.NoError(err)
+	ass.Equal(QuietPeriod, detection)
+	detection, err = ParseDetection("safra")
+	ass.NoError(err)
+	ass.Equal(Safra, detection)
+	_, err = ParseDetection("mattern")
+	ass.Error(err)
+}
@@ -0,0 +1,367 @@
+package topology
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"robots/internal/conf"
+	"robots/pkg/errors"
+	"robots/pkg/robot"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+type Kind string
+
+const (
+	Complete      Kind = "complete"
+	Ring          Kind = "ring"
+	Line          Kind = "line"
+	Star          Kind = "star"
+	Grid          Kind = "grid"
+	Torus         Kind = "torus"
+	RandomRegular Kind = "random-regular"
+	ErdosRenyi    Kind = "erdos-renyi"
+	SmallWorld    Kind = "small-world"
+	Explicit      Kind = "explicit"
+)
+
+// Topology restricts which robots are allowed to gossip with each other.
+// It is an adjacency list indexed by robot ID: a robot only initiates gossip
+// rounds with its neighbors. Replies always go back to the initiator, so an
+// edge only needs to exist on the initiator's side.
+// A Topology is immutable once built and safe for concurrent use.
+type Topology struct {
+	Kind      Kind
+	adjacency [][]robot.ID
+}
+
+// New builds the topology described by the configuration for NbrOfRobots robots.
+// Random topologies are generated from the given rng so runs can be replayed.
+func New(config conf.Config, rng *rand.Rand) (*Topology, error) {
+	n := config.NbrOfRobots
+	kind := Kind(config.Topology)
+	if kind == "" {
+		kind = Complete
+	}
+	var adjacency [][]robot.ID
+	var err error
+	switch kind {
+	case Complete:
+		adjacency = complete(n)
+	case Ring:
+		adjacency = ring(n)
+	case Line:
+		adjacency = line(n)
+	case Star:
+		adjacency = star(n)
+	case Grid:
+		adjacency, err = grid(n, config.TopologyGridWidth, false)
+	case Torus:
+		adjacency, err = grid(n, config.TopologyGridWidth, true)
+	case RandomRegular:
+		adjacency, err = randomRegular(n, config.TopologyDegree, rng)
+	case ErdosRenyi:
+		adjacency, err = erdosRenyi(n, config.TopologyProbability, rng)
+	case SmallWorld:
+		adjacency, err = smallWorld(n, config.TopologyDegree, config.TopologyProbability, rng)
+	case Explicit:
+		adjacency, err = parseAdjacency(n, config.TopologyAdjacency)
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.ErrUnknownTopology, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Topology{Kind: kind, adjacency: adjacency}, nil
+}
+
+// Neighbors Returns the robots the given robot is allowed to contact
+func (t *Topology) Neighbors(id robot.ID) []robot.ID {
+	if id.ToInt() < 0 || id.ToInt() >= len(t.adjacency) {
+		return nil
+	}
+	return t.adjacency[id]
+}
+
+// ChoosePeers picks up to k distinct random neighbors of the current robot, drawn from the rng.
+// Returns an empty slice when the robot is isolated and cannot gossip at all.
+func (t *Topology) ChoosePeers(current *robot.Robot, robots []*robot.Robot, k int, rng *rand.Rand) []*robot.Robot {
+	return lo.Map(robot.Sample(rng, t.Neighbors(current.ID), k), func(id robot.ID, _ int) *robot.Robot {
+		return robots[id]
+	})
+}
+
+// Diameter Returns the longest shortest path between two robots
+// or -1 if some robot cannot be reached from another one.
+func (t *Topology) Diameter() int {
+	diameter := 0
+	for source := range t.adjacency {
+		distances := t.distancesFrom(robot.ID(source))
+		for _, d := range distances {
+			if d < 0 {
+				return -1
+			}
+			diameter = max(diameter, d)
+		}
+	}
+	return diameter
+}
+
+// distancesFrom Breadth-first search over the directed adjacency list
+func (t *Topology) distancesFrom(source robot.ID) []int {
+	distances := make([]int, len(t.adjacency))
+	for i := range distances {
+		distances[i] = -1
+	}
+	distances[source] = 0
+	queue := []robot.ID{source}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range t.adjacency[current] {
+			if distances[next] < 0 {
+				distances[next] = distances[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distances
+}
+
+func complete(n int) [][]robot.ID {
+	adjacency := make([][]robot.ID, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				adjacency[i] = append(adjacency[i], robot.ID(j))
+			}
+		}
+	}
+	return adjacency
+}
+
+func ring(n int) [][]robot.ID {
+	edges := newEdges(n)
+	for i := 0; i < n; i++ {
+		edges.add(i, (i+1)%n)
+	}
+	return edges.adjacency()
+}
+
+func line(n int) [][]robot.ID {
+	edges := newEdges(n)
+	for i := 0; i+1 < n; i++ {
+		edges.add(i, i+1)
+	}
+	return edges.adjacency()
+}
+
+// star The robot 0 is the hub, every other robot only knows the hub
+func star(n int) [][]robot.ID {
+	edges := newEdges(n)
+	for i := 1; i < n; i++ {
+		edges.add(0, i)
+	}
+	return edges.adjacency()
+}
+
+// grid Lays robots row by row on a grid of the given width (square-ish by default).
+// A torus wraps rows and columns around and therefore needs a full rectangle.
+func grid(n, width int, wrap bool) ([][]robot.ID, error) {
+	if width <= 0 {
+		width = max(1, int(math.Sqrt(float64(n))))
+	}
+	if width > n {
+		return nil, fmt.Errorf("%w: grid width %d is larger than %d robots", errors.ErrInvalidTopology, width, n)
+	}
+	if wrap && n%width != 0 {
+		return nil, fmt.Errorf("%w: torus needs %d robots to be a multiple of the width %d", errors.ErrInvalidTopology, n, width)
+	}
+	rows := (n + width - 1) / width
+	edges := newEdges(n)
+	for i := 0; i < n; i++ {
+		row, col := i/width, i%width
+		switch {
+		case col+1 < width && i+1 < n:
+			edges.add(i, i+1)
+		case wrap && col+1 == width:
+			edges.add(i, row*width)
+		}
+		switch {
+		case row+1 < rows && i+width < n:
+			edges.add(i, i+width)
+		case wrap && row+1 == rows:
+			edges.add(i, col)
+		}
+	}
+	return edges.adjacency(), nil
+}
+
+// randomRegular Pairing model: every robot gets degree stubs which are randomly
+// matched together. Matchings with self-loops or parallel edges are rejected.
+func randomRegular(n, degree int, rng *rand.Rand) ([][]robot.ID, error) {
+	if degree <= 0 || degree >= n || (n*degree)%2 != 0 {
+		return nil, fmt.Errorf("%w: no %d-regular graph exists with %d robots", errors.ErrInvalidTopology, degree, n)
+	}
+	const maxAttempts = 1000
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		stubs := make([]int, 0, n*degree)
+		for i := 0; i < n; i++ {
+			for d := 0; d < degree; d++ {
+				stubs = append(stubs, i)
+			}
+		}
+		rng.Shuffle(len(stubs), func(i, j int) { stubs[i], stubs[j] = stubs[j], stubs[i] })
+		edges := newEdges(n)
+		valid := true
+		for i := 0; i < len(stubs); i += 2 {
+			a, b := stubs[i], stubs[i+1]
+			if a == b || edges.has(a, b) {
+				valid = false
+				break
+			}
+			edges.add(a, b)
+		}
+		if valid {
+			return edges.adjacency(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unable to generate a %d-regular graph after %d attempts", errors.ErrInvalidTopology, degree, maxAttempts)
+}
+
+// erdosRenyi G(n, p): each possible edge exists independently with probability p
+func erdosRenyi(n int, probability float64, rng *rand.Rand) ([][]robot.ID, error) {
+	if probability < 0 || probability > 1 {
+		return nil, fmt.Errorf("%w: probability %f should be between 0 and 1", errors.ErrInvalidTopology, probability)
+	}
+	edges := newEdges(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if rng.Float64() < probability {
+				edges.add(i, j)
+			}
+		}
+	}
+	return edges.adjacency(), nil
+}
+
+// smallWorld Watts–Strogatz: a ring lattice where each robot knows its degree/2
+// closest robots on each side, then every edge is rewired with probability beta.
+func smallWorld(n, degree int, beta float64, rng *rand.Rand) ([][]robot.ID, error) {
+	if degree <= 0 || degree%2 != 0 || degree >= n {
+		return nil, fmt.Errorf("%w: small-world degree %d should be even and lower than %d", errors.ErrInvalidTopology, degree, n)
+	}
+	if beta < 0 || beta > 1 {
+		return nil, fmt.Errorf("%w: probability %f should be between 0 and 1", errors.ErrInvalidTopology, beta)
+	}
+	edges := newEdges(n)
+	for i := 0; i < n; i++ {
+		for k := 1; k <= degree/2; k++ {
+			edges.add(i, (i+k)%n)
+		}
+	}
+	for i := 0; i < n; i++ {
+		for k := 1; k <= degree/2; k++ {
+			j := (i + k) % n
+			if !edges.has(i, j) || rng.Float64() >= beta || len(edges.neighbors[i]) >= n-1 {
+				continue
+			}
+			target := rng.Intn(n)
+			for target == i || edges.has(i, target) {
+				target = rng.Intn(n)
+			}
+			edges.remove(i, j)
+			edges.add(i, target)
+		}
+	}
+	return edges.adjacency(), nil
+}
+
+// parseAdjacency Reads an explicit adjacency list such as "0:1,2;1:0;2:0".
+// Edges are directed: a robot only gossips with the robots listed after it.
+func parseAdjacency(n int, adjacencyList string) ([][]robot.ID, error) {
+	adjacency := make([][]robot.ID, n)
+	if strings.TrimSpace(adjacencyList) == "" {
+		return nil, fmt.Errorf("%w: explicit topology requires an adjacency list", errors.ErrInvalidTopology)
+	}
+	for _, entry := range strings.Split(adjacencyList, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		source, targets, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("%w: malformed adjacency entry %q", errors.ErrInvalidTopology, entry)
+		}
+		from, err := parseID(source, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range strings.Split(targets, ",") {
+			if strings.TrimSpace(target) == "" {
+				continue
+			}
+			to, err := parseID(target, n)
+			if err != nil {
+				return nil, err
+			}
+			if to != from && !lo.Contains(adjacency[from], to) {
+				adjacency[from] = append(adjacency[from], to)
+			}
+		}
+	}
+	return adjacency, nil
+}
+
+func parseID(value string, n int) (robot.ID, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || id < 0 || id >= n {
+		return 0, fmt.Errorf("%w: robot %q doesn't exist", errors.ErrInvalidTopology, value)
+	}
+	return robot.ID(id), nil
+}
+
+// edges Undirected edge set used while generating a topology
+type edges struct {
+	neighbors []map[int]struct{}
+}
+
+func newEdges(n int) edges {
+	neighbors := make([]map[int]struct{}, n)
+	for i := range neighbors {
+		neighbors[i] = make(map[int]struct{})
+	}
+	return edges{neighbors: neighbors}
+}
+
+func (e edges) add(a, b int) {
+	if a == b {
+		return
+	}
+	e.neighbors[a][b] = struct{}{}
+	e.neighbors[b][a] = struct{}{}
+}
+
+func (e edges) remove(a, b int) {
+	delete(e.neighbors[a], b)
+	delete(e.neighbors[b], a)
+}
+
+func (e edges) has(a, b int) bool {
+	_, ok := e.neighbors[a][b]
+	return ok
+}
+
+func (e edges) adjacency() [][]robot.ID {
+	adjacency := make([][]robot.ID, len(e.neighbors))
+	for i, neighbors := range e.neighbors {
+		ids := lo.Keys(neighbors)
+		sort.Ints(ids)
+		adjacency[i] = lo.Map(ids, func(id int, _ int) robot.ID { return robot.ID(id) })
+	}
+	return adjacency
+}
@@ -0,0 +1,142 @@
+package topology
+
+import (
+	"math/rand"
+	"robots/internal/conf"
+	"robots/pkg/errors"
+	"robots/pkg/robot"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopology_New(t *testing.T) {
+	ass := assert.New(t)
+
+	tests := []struct {
+		name      string
+		config    conf.Config
+		degrees   []int
+		diameter  int
+		neighbors map[robot.ID][]robot.ID
+	}{
+		{
+			name:     "complete",
+			config:   conf.Config{NbrOfRobots: 4, Topology: "complete"},
+			degrees:  []int{3, 3, 3, 3},
+			diameter: 1,
+		},
+		{
+			name:     "default is complete",
+			config:   conf.Config{NbrOfRobots: 3},
+			degrees:  []int{2, 2, 2},
+			diameter: 1,
+		},
+		{
+			name:      "ring",
+			config:    conf.Config{NbrOfRobots: 6, Topology: "ring"},
+			degrees:   []int{2, 2, 2, 2, 2, 2},
+			diameter:  3,
+			neighbors: map[robot.ID][]robot.ID{0: {1, 5}},
+		},
+		{
+			name:      "line",
+			config:    conf.Config{NbrOfRobots: 4, Topology: "line"},
+			degrees:   []int{1, 2, 2, 1},
+			diameter:  3,
+			neighbors: map[robot.ID][]robot.ID{1: {0, 2}},
+		},
+		{
+			name:     "star",
+			config:   conf.Config{NbrOfRobots: 5, Topology: "star"},
+			degrees:  []int{4, 1, 1, 1, 1},
+			diameter: 2,
+		},
+		{
+			name:      "grid",
+			config:    conf.Config{NbrOfRobots: 9, Topology: "grid"},
+			degrees:   []int{2, 3, 2, 3, 4, 3, 2, 3, 2},
+			diameter:  4,
+			neighbors: map[robot.ID][]robot.ID{4: {1, 3, 5, 7}},
+		},
+		{
+			name:      "torus",
+			config:    conf.Config{NbrOfRobots: 9, Topology: "torus"},
+			degrees:   []int{4, 4, 4, 4, 4, 4, 4, 4, 4},
+			diameter:  2,
+			neighbors: map[robot.ID][]robot.ID{0: {1, 2, 3, 6}},
+		},
+		{
+			name:      "explicit",
+			config:    conf.Config{NbrOfRobots: 3, Topology: "explicit", TopologyAdjacency: "0:1;1:2;2:0"},
+			degrees:   []int{1, 1, 1},
+			diameter:  2,
+			neighbors: map[robot.ID][]robot.ID{0: {1}},
+		},
+		{
+			name:     "explicit disconnected",
+			config:   conf.Config{NbrOfRobots: 3, Topology: "explicit", TopologyAdjacency: "0:1;1:0"},
+			degrees:  []int{1, 1, 0},
+			diameter: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := New(tt.config, rand.New(rand.NewSource(1)))
+			ass.NoError(err)
+			for id, degree := range tt.degrees {
+				ass.Len(graph.Neighbors(robot.ID(id)), degree, "robot %d", id)
+			}
+			for id, neighbors := range tt.neighbors {
+				ass.Equal(neighbors, graph.Neighbors(id))
+			}
+			ass.Equal(tt.diameter, graph.Diameter())
+		})
+	}
+}
+
+func TestTopology_RandomGraphs(t *testing.T) {
+	ass := assert.New(t)
+	rng := rand.New(rand.NewSource(42))
+
+	regular, err := New(conf.Config{NbrOfRobots: 20, Topology: "random-regular", TopologyDegree: 3}, rng)
+	ass.NoError(err)
+	for id := 0; id < 20; id++ {
+		ass.Len(regular.Neighbors(robot.ID(id)), 3)
+		ass.NotContains(regular.Neighbors(robot.ID(id)), robot.ID(id))
+	}
+
+	smallWorld, err := New(conf.Config{NbrOfRobots: 20, Topology: "small-world", TopologyDegree: 4, TopologyProbability: 0.2}, rng)
+	ass.NoError(err)
+	edges := 0
+	for id := 0; id < 20; id++ {
+		edges += len(smallWorld.Neighbors(robot.ID(id)))
+	}
+	ass.Equal(20*4, edges, "rewiring should keep the number of edges")
+
+	empty, err := New(conf.Config{NbrOfRobots: 5, Topology: "erdos-renyi", TopologyProbability: 0}, rng)
+	ass.NoError(err)
+	ass.Equal(-1, empty.Diameter())
+
+	full, err := New(conf.Config{NbrOfRobots: 5, Topology: "erdos-renyi", TopologyProbability: 1}, rng)
+	ass.NoError(err)
+	ass.Equal(1, full.Diameter())
+}
+
+func TestTopology_InvalidParameters(t *testing.T) {
+	ass := assert.New(t)
+	rng := rand.New(rand.NewSource(1))
+
+	_, err := New(conf.Config{NbrOfRobots: 4, Topology: "hypercube"}, rng)
+	ass.ErrorIs(err, errors.ErrUnknownTopology)
+
+	_, err = New(conf.Config{NbrOfRobots: 5, Topology: "random-regular", TopologyDegree: 3}, rng)
+	ass.ErrorIs(err, errors.ErrInvalidTopology)
+
+	_, err = New(conf.Config{NbrOfRobots: 7, Topology: "torus", TopologyGridWidth: 3}, rng)
+	ass.ErrorIs(err, errors.ErrInvalidTopology)
+
+	_, err = New(conf.Config{NbrOfRobots: 3, Topology: "explicit", TopologyAdjacency: "0:5"}, rng)
+	ass.ErrorIs(err, errors.ErrInvalidTopology)
+}
@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"fmt"
+	"robots/pkg/errors"
+	"robots/pkg/robot"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Transport carries encoded messages between robots.
+// Sending never blocks: a message that can't be delivered is dropped and
+// Send returns false, like on the gossip channels.
+type Transport interface {
+	Send(from, to robot.ID, msg []byte) bool
+	Inbox(id robot.ID) <-chan []byte
+}
+
+// Memory Transport over buffered channels, one inbox per robot
+type Memory struct {
+	inboxes []chan []byte
+}
+
+func NewMemory(nbrOfRobots, bufferSize int) *Memory {
+	inboxes := make([]chan []byte, nbrOfRobots)
+	for i := range inboxes {
+		inboxes[i] = make(chan []byte, bufferSize)
+	}
+	return &Memory{inboxes: inboxes}
+}
+
+func (m *Memory) Send(_, to robot.ID, msg []byte) bool {
+	if to < 0 || int(to) >= len(m.inboxes) {
+		return false
+	}
+	select {
+	case m.inboxes[to] <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *Memory) Inbox(id robot.ID) <-chan []byte {
+	return m.inboxes[id]
+}
+
+// Partition Transport dropping the messages between robots of different groups.
+// Robots that aren't part of any group share the same implicit group.
+// Groups can be changed at any time to split or heal the network.
+type Partition struct {
+	Transport
+	mu     sync.RWMutex
+	groups map[robot.ID]int
+}
+
+func NewPartition(transport Transport) *Partition {
+	return &Partition{Transport: transport}
+}
+
+// Split Isolates the groups of robots from each other
+func (p *Partition) Split(groups ...[]robot.ID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.groups = make(map[robot.ID]int)
+	for i, group := range groups {
+		for _, id := range group {
+			p.groups[id] = i + 1
+		}
+	}
+}
+
+// Heal Lets every robot reach every other robot again
+func (p *Partition) Heal() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.groups = nil
+}
+
+// Reachable Tells if a message from a robot can reach another one
+func (p *Partition) Reachable(from, to robot.ID) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.groups[from] == p.groups[to]
+}
+
+func (p *Partition) Send(from, to robot.ID, msg []byte) bool {
+	if !p.Reachable(from, to) {
+		return false
+	}
+	return p.Transport.Send(from, to, msg)
+}
+
+// ParseGroups Reads groups of robot IDs such as "0,1,2;3,4"
+func ParseGroups(nbrOfRobots int, value string) ([][]robot.ID, error) {
+	var groups [][]robot.ID
+	seen := make(map[robot.ID]bool)
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		var group []robot.ID
+		for _, field := range strings.Split(entry, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || id < 0 || id >= nbrOfRobots || seen[robot.ID(id)] {
+				return nil, fmt.Errorf("%w: %q", errors.ErrInvalidPartition, field)
+			}
+			seen[robot.ID(id)] = true
+			group = append(group, robot.ID(id))
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
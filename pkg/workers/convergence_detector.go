@@ -2,6 +2,8 @@ package workers
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
 	"log/slog"
 	"robots/internal/conf"
 	"robots/pkg/events"
@@ -22,18 +24,26 @@ import (
 // case and fail to write the secret. This can cause no file to be created even though a robot
 // has completed the secret. Using a buffered channel or a sync.Once ensures that the secret
 // is reliably written exactly once.
+// With a leader election, the robot runs for the election instead (see WithCandidacy).
 type ConvergenceDetectorWorker struct {
 	Config      conf.Config
 	Log         *slog.Logger
 	Robot       *robot.Robot
 	Name        events.WorkerName
 	DomainEvent chan events.Event
+	Candidacy   chan<- struct{}
 }
 
 func NewConvergenceDetectorWorker(config conf.Config, log *slog.Logger, robot *robot.Robot, DomainEvent chan events.Event) ConvergenceDetectorWorker {
 	return ConvergenceDetectorWorker{Config: config, Log: log, Robot: robot, DomainEvent: DomainEvent}
 }
 
+// WithCandidacy makes the robot run for the election once converged, rather than writing itself
+func (w ConvergenceDetectorWorker) WithCandidacy(candidacy chan<- struct{}) ConvergenceDetectorWorker {
+	w.Candidacy = candidacy
+	return w
+}
+
 func (w ConvergenceDetectorWorker) WithName(name string) Worker {
 	w.Name = events.WorkerName(name)
 	return w
@@ -43,15 +53,39 @@ func (w ConvergenceDetectorWorker) GetName() events.WorkerName {
 	return w.Name
 }
 
+// Run The robot is quiet once its version vector stopped changing for the quiet period.
+// Until the vector is first observed, the last update of the robot is used instead.
+// Time is read on the robot's own clock: a fast clock ends the quiet period too early.
+//
+// A failed verification is terminal: MergeSecretPart never replaces a part held,
+// so the robot can never complete. It is reported once and the worker stops.
 func (w ConvergenceDetectorWorker) Run(ctx context.Context) error {
-	ticker := time.NewTicker(time.Second)
+	ticker := w.Robot.Time.NewTicker(time.Second)
 	defer ticker.Stop()
+	var vector robot.VersionVector
+	quietSince := w.Robot.Snapshot().LastUpdatedAt
+	realQuietSince := time.Now().UTC().Add(quietSince.Sub(w.Robot.Time.Now()))
 	for {
 		select {
 		case <-ticker.C:
-			elapsed := w.Robot.LastUpdatedAt.Add(w.Config.QuietPeriod).Before(time.Now().UTC())
-			if elapsed && w.Robot.IsSecretCompleted(w.Config.EndOfSecret) {
-				w.sendWinnerElectedEvent(ctx, w.Robot.ID)
+			snapshot, current := w.Robot.Snapshot(), w.Robot.VersionVector()
+			if vector != nil && current.Compare(vector) != robot.Equal {
+				quietSince, realQuietSince = w.Robot.Time.Now(), time.Now().UTC()
+			}
+			vector = current
+			if snapshot.VerificationFailed() {
+				w.sendSecretVerificationFailedEvent(ctx, snapshot)
+				return nil
+			}
+			elapsed := quietSince.Add(w.Config.QuietPeriod).Before(w.Robot.Time.Now())
+			if elapsed && snapshot.IsSecretCompleted(w.Config.EndOfSecret) {
+				w.Log.Debug(fmt.Sprintf("Robot %d has been quiet for %s on its clock, %s in real time", w.Robot.ID,
+					w.Robot.Time.Now().Sub(quietSince).Round(time.Millisecond), time.Since(realQuietSince).Round(time.Millisecond)))
+				if w.Candidacy != nil {
+					runForElection(w.Candidacy)
+					continue
+				}
+				w.sendWinnerElectedEvent(ctx, w.Robot.ID, current)
 			}
 		case <-ctx.Done():
 			w.Log.Debug("Context done, stopping domainEvent send")
@@ -60,12 +94,32 @@ func (w ConvergenceDetectorWorker) Run(ctx context.Context) error {
 	}
 }
 
-func (w ConvergenceDetectorWorker) sendWinnerElectedEvent(ctx context.Context, id robot.ID) {
+// sendSecretVerificationFailedEvent The failure is terminal and reported once, the worker waits for room in the buffer
+func (w ConvergenceDetectorWorker) sendSecretVerificationFailedEvent(ctx context.Context, snapshot robot.Snapshot) {
+	expected, actual, _ := snapshot.Verify()
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventSecretVerificationFailed,
+		CreatedAt: time.Now().UTC(),
+		Payload: events.SecretVerificationFailedEvent{
+			ID: w.Robot.ID, Expected: hex.EncodeToString(expected[:]), Actual: hex.EncodeToString(actual[:]),
+		},
+		Vector:  w.Robot.VersionVector(),
+		Lamport: w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	}
+}
+
+func (w ConvergenceDetectorWorker) sendWinnerElectedEvent(ctx context.Context, id robot.ID, vector robot.VersionVector) {
 	select {
 	case w.DomainEvent <- events.Event{
 		EventType: events.EventWinnerElected,
 		CreatedAt: time.Now().UTC(),
-		Payload:   events.WinnerElectedEvent{ID: id.ToInt()},
+		Payload:   events.WinnerElectedEvent{ID: id.ToInt(), SecretID: w.Robot.Secret},
+		Vector:    vector,
+		Lamport:   w.Robot.Clock.Tick(),
 	}:
 	case <-ctx.Done():
 		w.Log.Debug("Context done, stopping domainEvent send")
@@ -52,7 +52,7 @@ func (w ConvergenceObserverWorker) Run(ctx context.Context) error {
 		case <-ticker.C:
 			allConverged := true
 			for _, r := range w.Robots {
-				if !r.IsSecretCompleted(w.config.EndOfSecret) {
+				if !r.Snapshot().IsSecretCompleted(w.config.EndOfSecret) {
 					allConverged = false
 				}
 			}
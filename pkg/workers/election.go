@@ -0,0 +1,132 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"robots/internal/conf"
+	"robots/pkg/election"
+	"robots/pkg/events"
+	"robots/pkg/robot"
+	"robots/pkg/transport"
+	pb "robots/proto"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ElectionWorker runs the leader election choosing the single robot writing the secret.
+// The robot runs for the election once its convergence detector (or the
+// termination detection) signals it on the candidacy channel. Election
+// messages go through the transport, which may be partitioned, so robots
+// don't rely on a process-local sync.Once to agree on the writer.
+// The elected robot emits the winner event, the only one in this mode.
+type ElectionWorker struct {
+	Config      conf.Config
+	Log         *slog.Logger
+	Name        events.WorkerName
+	Robot       *robot.Robot
+	Elector     election.Elector
+	Transport   transport.Transport
+	Candidacy   <-chan struct{}
+	DomainEvent chan events.Event
+}
+
+func NewElectionWorker(config conf.Config, log *slog.Logger, r *robot.Robot, transport transport.Transport, candidacy <-chan struct{}, domainEvent chan events.Event) ElectionWorker {
+	algorithm, _ := election.ParseAlgorithm(config.Election)
+	rng := rand.New(rand.NewSource(config.Seed + int64(r.ID)))
+	elector := election.New(algorithm, r.ID, config.NbrOfRobots, config.ElectionTimeout, config.ElectionLease, rng)
+	return ElectionWorker{Config: config, Log: log, Robot: r, Elector: elector, Transport: transport, Candidacy: candidacy, DomainEvent: domainEvent}
+}
+
+func (w ElectionWorker) WithName(name string) Worker {
+	w.Name = events.WorkerName(name)
+	return w
+}
+
+func (w ElectionWorker) GetName() events.WorkerName {
+	return w.Name
+}
+
+// Run The elector isn't safe for concurrent use, the worker is its only caller.
+// A restarted worker keeps the same elector and resumes the election.
+func (w ElectionWorker) Run(ctx context.Context) error {
+	ticker := w.Robot.Time.NewTicker(w.Config.ElectionTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.Candidacy:
+			w.apply(ctx, w.Elector.Campaign(w.Robot.Time.Now()))
+		case electionMsg := <-w.Transport.Inbox(w.Robot.ID):
+			var msg pb.ElectionMessage
+			if err := proto.Unmarshal(electionMsg, &msg); err != nil {
+				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
+				continue
+			}
+			w.Robot.Clock.Witness(msg.Lamport)
+			w.apply(ctx, w.Elector.Handle(&msg, w.Robot.Time.Now()))
+		case <-ticker.C:
+			w.apply(ctx, w.Elector.Tick(w.Robot.Time.Now()))
+		case <-ctx.Done():
+			w.Log.Debug("Context done, stopping election")
+			return nil
+		}
+	}
+}
+
+// apply Sends the messages of an election step and turns its reports into events
+func (w ElectionWorker) apply(ctx context.Context, step election.Step) {
+	for _, outgoing := range step.Messages {
+		outgoing.Message.Lamport = w.Robot.Clock.Tick()
+		msg, err := proto.Marshal(outgoing.Message)
+		if err != nil {
+			w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+			continue
+		}
+		if !w.Transport.Send(w.Robot.ID, outgoing.To, msg) {
+			w.Log.Debug(fmt.Sprintf("Election message from robot %d to robot %d dropped", w.Robot.ID, outgoing.To))
+		}
+	}
+	for _, report := range step.Reports {
+		switch report.Kind {
+		case election.Candidacy:
+			w.sendEvent(ctx, events.EventElectionCandidacy, events.ElectionCandidacyEvent{ID: report.Candidate, Term: report.Term})
+		case election.Vote:
+			w.sendEvent(ctx, events.EventElectionVote, events.ElectionVoteEvent{
+				VoterID: report.Voter, CandidateID: report.Candidate, Term: report.Term, Granted: report.Granted,
+			})
+		case election.Elected:
+			w.sendEvent(ctx, events.EventLeaderElected, events.LeaderElectedEvent{ID: report.Candidate, Term: report.Term, Votes: report.Votes})
+			w.sendEvent(ctx, events.EventWinnerElected, events.WinnerElectedEvent{ID: report.Candidate.ToInt(), SecretID: w.Robot.Secret, Term: report.Term})
+		case election.SteppedDown:
+			w.sendEvent(ctx, events.EventLeaderSteppedDown, events.LeaderSteppedDownEvent{ID: report.Candidate, Term: report.Term})
+		case election.SplitBrain:
+			leader, _ := w.Elector.Leader()
+			w.sendEvent(ctx, events.EventSplitBrain, events.SplitBrainEvent{ID: w.Robot.ID, Leader: leader, Other: report.Candidate})
+		}
+	}
+}
+
+// sendEvent The outcome of the election must not be lost, the worker waits for room in the buffer
+func (w ElectionWorker) sendEvent(ctx context.Context, eventType events.EventType, payload any) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: eventType,
+		CreatedAt: time.Now().UTC(),
+		Payload:   payload,
+		Vector:    w.Robot.VersionVector(),
+		Lamport:   w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	}
+}
+
+// runForElection Signals the election worker without blocking, a pending candidacy is enough
+func runForElection(candidacy chan<- struct{}) {
+	select {
+	case candidacy <- struct{}{}:
+	default:
+	}
+}
@@ -23,12 +23,19 @@ type MergeSecretWorker struct {
 	Name        events.WorkerName
 	Robot       *robot.Robot
 	DomainEvent chan events.Event
+	Secrets     robot.Secrets // Other secrets whose updates come through the robot's channels
 }
 
 func NewMergeSecretWorker(logger *slog.Logger, robot *robot.Robot, DomainEvent chan events.Event) MergeSecretWorker {
 	return MergeSecretWorker{Log: logger, Robot: robot, DomainEvent: DomainEvent}
 }
 
+// WithSecrets lets the worker merge the updates of every secret disseminated at once
+func (w MergeSecretWorker) WithSecrets(secrets robot.Secrets) MergeSecretWorker {
+	w.Secrets = secrets
+	return w
+}
+
 func (w MergeSecretWorker) WithName(name string) Worker {
 	w.Name = events.WorkerName(name)
 	return w
@@ -42,6 +49,8 @@ func (w MergeSecretWorker) GetName() events.WorkerName {
 // Responsibilities:
 // - Merge new SecretParts into the robot's state.
 // - Update LastUpdatedAt when new parts are added.
+// - Record the parts in transit when a global snapshot is in progress.
+// - Merge the parts of another secret into the robot's state for that secret.
 // Invariant enforcement (delegated to Robot.MergeSecretPart):
 // - Monotonicity: robot never loses a SecretPart.
 // - Uniqueness: each index maps to exactly one word; conflicting parts trigger panic.
@@ -61,10 +70,16 @@ func (w MergeSecretWorker) Run(ctx context.Context) error {
 				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
 				continue
 			}
-			secretParts := robot.FromSecretPartsPb(gossipUpdate.SecretParts)
-			for _, secretPart := range secretParts {
-				w.mergeSecretPart(sendInvariantViolationEvent)(ctx, secretPart)
+			target := w
+			if gossipUpdate.SecretId != string(w.Robot.Secret) {
+				r, ok := w.Secrets.Robot(robot.SecretID(gossipUpdate.SecretId), w.Robot.ID)
+				if !ok {
+					w.Log.Debug(fmt.Sprintf("Secret %q doesn't exist", gossipUpdate.SecretId))
+					continue
+				}
+				target.Robot = r
 			}
+			target.merge(ctx, &gossipUpdate)
 		case <-ctx.Done():
 			w.Log.Debug("Context done, stopping domainEvent send")
 			return nil
@@ -72,6 +87,23 @@ func (w MergeSecretWorker) Run(ctx context.Context) error {
 	}
 }
 
+// merge Merges the parts of an update, unless it is a marker
+func (w MergeSecretWorker) merge(ctx context.Context, gossipUpdate *pb.GossipUpdate) {
+	w.Robot.Clock.Witness(gossipUpdate.Lamport)
+	secretParts := robot.FromSecretPartsPb(gossipUpdate.SecretParts)
+	// Parts are recorded as in transit before being merged
+	w.Robot.ObserveSnapshot(robot.ID(gossipUpdate.SenderId), robot.UpdateLink, gossipUpdate.SnapshotEpoch, secretParts)
+	if gossipUpdate.Marker {
+		return
+	}
+	if len(gossipUpdate.SecretParts) > 0 {
+		w.Robot.Termination.Received()
+	}
+	for _, secretPart := range secretParts {
+		w.mergeSecretPart(sendInvariantViolationEvent)(ctx, secretPart)
+	}
+}
+
 func (w MergeSecretWorker) mergeSecretPart(
 	recoverFunc func(ctx context.Context, r *robot.Robot, event chan events.Event),
 ) func(ctx context.Context, secretPart robot.SecretPart) {
@@ -92,6 +124,7 @@ func sendInvariantViolationEvent(ctx context.Context, r *robot.Robot, event chan
 		EventType: events.EventInvariantViolationSameIndexDiffWords,
 		CreatedAt: time.Now().UTC(),
 		Payload:   events.InvariantViolationEvent{ID: r.ID},
+		Lamport:   r.Clock.Tick(),
 	}:
 	case <-ctx.Done():
 		return
@@ -0,0 +1,165 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"robots/pkg/events"
+	"robots/pkg/robot"
+	pb "robots/proto"
+	"time"
+
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/proto"
+)
+
+// MerkleExchangeWorker reconciles a robot with its peers by comparing Merkle trees.
+// Each step compares the received node hashes with the robot's own tree and
+// only descends into the subtrees that differ. Once differing leaves are
+// found, both robots send each other the parts stored under those leaves.
+//
+// Every step carries the cumulated rounds, bytes and parts of the exchange,
+// so the robot ending the exchange reports its whole cost in a single event.
+type MerkleExchangeWorker struct {
+	Log         *slog.Logger
+	Name        events.WorkerName
+	Robot       *robot.Robot
+	Robots      []*robot.Robot
+	DomainEvent chan events.Event
+}
+
+func NewMerkleExchangeWorker(log *slog.Logger, robot *robot.Robot, robots []*robot.Robot, domainEvent chan events.Event) MerkleExchangeWorker {
+	return MerkleExchangeWorker{Log: log, Robot: robot, Robots: robots, DomainEvent: domainEvent}
+}
+
+func (w MerkleExchangeWorker) WithName(name string) Worker {
+	w.Name = events.WorkerName(name)
+	return w
+}
+
+func (w MerkleExchangeWorker) GetName() events.WorkerName {
+	return w.Name
+}
+
+func (w MerkleExchangeWorker) Run(ctx context.Context) error {
+	for {
+		select {
+		case exchangeMsg := <-w.Robot.GossipMerkle:
+			var exchange pb.MerkleExchange
+			if err := proto.Unmarshal(exchangeMsg, &exchange); err != nil {
+				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
+				continue
+			}
+			w.Robot.Clock.Witness(exchange.Lamport)
+			if exchange.SenderId < 0 || int(exchange.SenderId) >= len(w.Robots) {
+				w.Log.Debug(fmt.Sprintf("Robot %d doesn't exist", exchange.SenderId))
+				continue
+			}
+			if int(exchange.Leaves) != w.Robot.MerkleLeaves() {
+				w.Log.Debug(fmt.Sprintf("Robot %d has a Merkle tree of a different shape", exchange.SenderId))
+				continue
+			}
+			w.reconcile(ctx, &exchange, len(exchangeMsg))
+		case <-ctx.Done():
+			w.Log.Debug("Context done, stopping domainEvent send")
+			return nil
+		}
+	}
+}
+
+func (w MerkleExchangeWorker) reconcile(ctx context.Context, exchange *pb.MerkleExchange, size int) {
+	peer := w.Robots[exchange.SenderId]
+	rounds, bytes, parts := exchange.Rounds+1, exchange.Bytes+uint64(size), exchange.Parts
+
+	// The peer found differing leaves and wants our parts
+	sentParts, sentBytes := w.sendLeafParts(ctx, peer, lo.Map(exchange.RequestedLeaves, func(item uint32, _ int) int {
+		return int(item)
+	}))
+	parts, bytes = parts+sentParts, bytes+sentBytes
+
+	inner, leaves := w.Robot.DiffMerkleNodes(exchange.Nodes)
+	sentParts, sentBytes = w.sendLeafParts(ctx, peer, leaves)
+	parts, bytes = parts+sentParts, bytes+sentBytes
+
+	if len(inner) == 0 && len(leaves) == 0 {
+		w.sendMerkleExchangeEvent(ctx, exchange.ExchangeId, rounds, bytes, parts)
+		return
+	}
+	children := lo.FlatMap(inner, func(position int, _ int) []int {
+		return []int{2 * position, 2*position + 1}
+	})
+	msg, err := proto.Marshal(&pb.MerkleExchange{
+		SenderId:        int32(w.Robot.ID),
+		ExchangeId:      exchange.ExchangeId,
+		Leaves:          exchange.Leaves,
+		Nodes:           w.Robot.MerkleNodes(children),
+		RequestedLeaves: lo.Map(leaves, func(item int, _ int) uint32 { return uint32(item) }),
+		Rounds:          rounds,
+		Bytes:           bytes,
+		Parts:           parts,
+		Lamport:         w.Robot.Clock.Tick(),
+	})
+	if err != nil {
+		w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+		return
+	}
+	select {
+	case peer.GossipMerkle <- msg:
+	default:
+		w.Log.Debug("GossipMerkle channel is full, dropping message")
+	}
+}
+
+// sendLeafParts Sends the parts stored under the given leaves as a regular update.
+// Returns the number of parts and bytes sent.
+func (w MerkleExchangeWorker) sendLeafParts(ctx context.Context, peer *robot.Robot, leaves []int) (uint32, uint64) {
+	if len(leaves) == 0 {
+		return 0, 0
+	}
+	secretParts := w.Robot.LeafParts(leaves)
+	if len(secretParts) == 0 {
+		return 0, 0
+	}
+	var parts uint32
+	var size uint64
+	// In the outbox, so the snapshot epoch of the update can't be overtaken
+	w.Robot.Outbox(func() {
+		msg, err := proto.Marshal(&pb.GossipUpdate{SecretParts: robot.ToSecretPartsPb(secretParts), SenderId: int32(w.Robot.ID), Lamport: w.Robot.Clock.Tick(), SnapshotEpoch: w.Robot.SnapshotEpoch()})
+		if err != nil {
+			w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+			return
+		}
+		w.Robot.Termination.Sent()
+		select {
+		case peer.GossipUpdate <- msg:
+			parts, size = uint32(len(secretParts)), uint64(len(msg))
+		case <-ctx.Done():
+			w.Robot.Termination.Dropped()
+		default:
+			w.Robot.Termination.Dropped()
+			w.Log.Debug("GossipUpdate channel is full, dropping message")
+		}
+	})
+	return parts, size
+}
+
+func (w MerkleExchangeWorker) sendMerkleExchangeEvent(ctx context.Context, exchangeID uint64, rounds uint32, bytes uint64, parts uint32) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventMerkleExchange,
+		CreatedAt: time.Now().UTC(),
+		Payload: events.MerkleExchangeEvent{
+			ExchangeID: exchangeID,
+			ID:         w.Robot.ID,
+			Rounds:     int(rounds),
+			Bytes:      int(bytes),
+			Parts:      int(parts),
+		},
+		Lamport: w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	default:
+		w.Log.Debug(fmt.Sprintf("[%s] Buffer is full", w.Name))
+	}
+}
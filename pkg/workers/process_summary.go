@@ -1,33 +1,47 @@
 package workers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
+	"robots/internal/conf"
 	"robots/pkg/events"
 	"robots/pkg/robot"
 	pb "robots/proto"
 	"time"
 
 	"google.golang.org/protobuf/proto"
-
-	"github.com/samber/lo"
 )
 
 // ProcessSummaryWorker handles incoming gossip summaries from other robots.
 // It tries to send the corresponding updates to the target robots without blocking.
+// In push-pull mode, it also sends back its own summary so both sides are
+// reconciled within the same round.
+// When the sender's version vector covers our own, the sender already knows
+// every part we hold and no update is sent.
+// Summaries of other secrets, embedded in the same message, are processed
+// with the robot's state for those secrets.
 // If the receiver channel is full, the message is dropped to keep the system responsive.
 // Channel capacity can be monitored via metrics if needed.
 type ProcessSummaryWorker struct {
+	Config      conf.Config
 	Log         *slog.Logger
 	Name        events.WorkerName
 	robot       *robot.Robot
 	Robots      []*robot.Robot
 	DomainEvent chan events.Event
+	Secrets     robot.Secrets // Other secrets whose summaries come through the robot's channels
+}
+
+func NewProcessSummaryWorker(config conf.Config, logger *slog.Logger, robot *robot.Robot, robots []*robot.Robot, domainEvent chan events.Event) ProcessSummaryWorker {
+	return ProcessSummaryWorker{Config: config, Log: logger, robot: robot, Robots: robots, DomainEvent: domainEvent}
 }
 
-func NewProcessSummaryWorker(logger *slog.Logger, robot *robot.Robot, robots []*robot.Robot, domainEvent chan events.Event) ProcessSummaryWorker {
-	return ProcessSummaryWorker{Log: logger, robot: robot, Robots: robots, DomainEvent: domainEvent}
+// WithSecrets lets the worker process the summaries of every secret disseminated at once
+func (w ProcessSummaryWorker) WithSecrets(secrets robot.Secrets) ProcessSummaryWorker {
+	w.Secrets = secrets
+	return w
 }
 
 func (w ProcessSummaryWorker) WithName(name string) Worker {
@@ -48,25 +62,13 @@ func (w ProcessSummaryWorker) Run(ctx context.Context) error {
 				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
 				continue
 			}
-			indexes := lo.Map(gossipSummary.Indexes, func(item int64, _ int) int {
-				return int(item)
-			})
-			secretParts := w.robot.GetWordsToSend(indexes)
-			msg, err := proto.Marshal(&pb.GossipUpdate{SecretParts: robot.ToSecretPartsPb(secretParts)})
-			if err != nil {
-				w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
-				continue
-			}
-			if gossipSummary.SenderId < 0 || int(gossipSummary.SenderId) >= len(w.Robots) {
-				w.Log.Debug(fmt.Sprintf("Robot %d doesn't exist", gossipSummary.SenderId))
-				continue
-			}
-			receiver := w.Robots[gossipSummary.SenderId]
-			select {
-			case receiver.GossipUpdate <- msg:
-				w.sendMessageReceivedEvent(ctx, receiver.ID)
-			default:
-				w.Log.Debug("GossipUpdate channel is full, dropping message")
+			for _, summary := range append([]*pb.GossipSummary{&gossipSummary}, gossipSummary.Others...) {
+				target, ok := w.forSecret(robot.SecretID(summary.SecretId))
+				if !ok {
+					w.Log.Debug(fmt.Sprintf("Secret %q doesn't exist", summary.SecretId))
+					continue
+				}
+				target.process(ctx, summary)
 			}
 		case <-ctx.Done():
 			w.Log.Debug("Context done, stopping domainEvent send")
@@ -75,12 +77,144 @@ func (w ProcessSummaryWorker) Run(ctx context.Context) error {
 	}
 }
 
-func (w ProcessSummaryWorker) sendMessageReceivedEvent(ctx context.Context, receiverID robot.ID) {
+// forSecret The worker acting on the state of the robot for another secret, sharing its channels
+func (w ProcessSummaryWorker) forSecret(id robot.SecretID) (ProcessSummaryWorker, bool) {
+	if id == w.robot.Secret {
+		return w, true
+	}
+	r, ok := w.Secrets.Robot(id, w.robot.ID)
+	if !ok {
+		return w, false
+	}
+	w.robot, w.Robots = r, w.Secrets[id]
+	return w, true
+}
+
+// process Answers a summary with the parts its sender lacks
+func (w ProcessSummaryWorker) process(ctx context.Context, gossipSummary *pb.GossipSummary) {
+	w.robot.Clock.Witness(gossipSummary.Lamport)
+	if gossipSummary.SenderId < 0 || int(gossipSummary.SenderId) >= len(w.Robots) {
+		w.Log.Debug(fmt.Sprintf("Robot %d doesn't exist", gossipSummary.SenderId))
+		return
+	}
+	w.robot.ObserveSnapshot(robot.ID(gossipSummary.SenderId), robot.SummaryLink, gossipSummary.SnapshotEpoch, nil)
+	if gossipSummary.Marker {
+		return
+	}
+	indexes := robot.FromSummaryPb(gossipSummary)
+	secretParts := w.robot.GetWordsToSend(indexes)
+	receiver := w.Robots[gossipSummary.SenderId]
+	w.robot.RememberPeerIndexes(receiver.ID, indexes)
+	if len(gossipSummary.Digest) > 0 && bytes.Equal(gossipSummary.Digest, w.robot.Snapshot().Digest()) {
+		// Both robots hold exactly the same parts, nothing to reconcile
+		return
+	}
+	ordering := robot.Concurrent
+	if len(gossipSummary.Vector) > 0 {
+		ordering = robot.FromVersionVectorPb(gossipSummary.Vector).Compare(w.robot.VersionVector())
+	}
+	switch ordering {
+	case robot.Equal:
+		// Both robots integrated the same parts, nothing to reconcile
+		return
+	case robot.After:
+		w.Log.Debug(fmt.Sprintf("Robot %d already knows every part of robot %d", receiver.ID, w.robot.ID))
+	default:
+		if ordering == robot.Concurrent && len(gossipSummary.Vector) > 0 {
+			w.sendConcurrentKnowledgeEvent(ctx, receiver.ID)
+		}
+		w.sendUpdate(ctx, receiver, secretParts)
+	}
+	if gossipSummary.Mode == pb.GossipMode_PUSH_PULL {
+		w.sendSummary(ctx, receiver, w.replyVersion(gossipSummary.Version))
+	}
+}
+
+// sendUpdate Sends the parts the initiator lacks in the robot's outbox, so the snapshot epoch can't be overtaken
+func (w ProcessSummaryWorker) sendUpdate(ctx context.Context, receiver *robot.Robot, secretParts []robot.SecretPart) {
+	w.robot.Outbox(func() {
+		msg, err := proto.Marshal(&pb.GossipUpdate{
+			SecretParts:   robot.ToSecretPartsPb(secretParts),
+			SenderId:      int32(w.robot.ID),
+			Lamport:       w.robot.Clock.Tick(),
+			SnapshotEpoch: w.robot.SnapshotEpoch(),
+			SecretId:      string(w.robot.Secret),
+		})
+		if err != nil {
+			w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+			return
+		}
+		basic := len(secretParts) > 0
+		if basic {
+			w.robot.Termination.Sent()
+		}
+		select {
+		case receiver.GossipUpdate <- msg:
+			w.sendMessageReceivedEvent(ctx, receiver.ID, events.MessageUpdate)
+		default:
+			if basic {
+				w.robot.Termination.Dropped()
+			}
+			w.Log.Debug("GossipUpdate channel is full, dropping message")
+		}
+	})
+}
+
+// replyVersion The version of the initiator's summary, unless summaries are compact:
+// then each robot picks the smallest exact encoding for its own indexes
+func (w ProcessSummaryWorker) replyVersion(version pb.SummaryVersion) pb.SummaryVersion {
+	if configured, _ := robot.ParseSummaryVersion(w.Config.SummaryEncoding); configured == pb.SummaryVersion_COMPACT {
+		return configured
+	}
+	return version
+}
+
+// sendSummary Sends back our own indexes to the initiator of a push-pull round,
+// encoded with the version given by replyVersion.
+// The summary is flagged as pull so that the exchange stops after the initiator's update.
+func (w ProcessSummaryWorker) sendSummary(ctx context.Context, receiver *robot.Robot, version pb.SummaryVersion) {
+	w.robot.Outbox(func() {
+		summary := w.robot.Summary(version, w.Config.BloomFalsePositiveRate)
+		summary.Mode = pb.GossipMode_PULL
+		summary.Lamport = w.robot.Clock.Tick()
+		msg, err := proto.Marshal(summary)
+		if err != nil {
+			w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+			return
+		}
+		select {
+		case receiver.GossipSummary <- msg:
+			w.sendMessageReceivedEvent(ctx, receiver.ID, events.MessageSummary)
+		default:
+			w.Log.Debug("GossipSummary channel is full, dropping message")
+		}
+	})
+}
+
+func (w ProcessSummaryWorker) sendMessageReceivedEvent(ctx context.Context, receiverID robot.ID, kind events.MessageKind) {
 	select {
 	case w.DomainEvent <- events.Event{
 		EventType: events.EventMessageReceived,
 		CreatedAt: time.Now().UTC(),
-		Payload:   events.MessageReceivedEvent{ReceiverID: receiverID},
+		Payload:   events.MessageReceivedEvent{ReceiverID: receiverID, Kind: kind},
+		Vector:    w.robot.VersionVector(),
+		Lamport:   w.robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	default:
+		w.Log.Debug(fmt.Sprintf("[%s] Buffer is full", w.Name))
+	}
+}
+
+func (w ProcessSummaryWorker) sendConcurrentKnowledgeEvent(ctx context.Context, senderID robot.ID) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventConcurrentKnowledge,
+		CreatedAt: time.Now().UTC(),
+		Payload:   events.ConcurrentKnowledgeEvent{ReceiverID: w.robot.ID, SenderID: senderID},
+		Vector:    w.robot.VersionVector(),
+		Lamport:   w.robot.Clock.Tick(),
 	}:
 	case <-ctx.Done():
 		w.Log.Debug("Context done, stopping domainEvent send")
@@ -41,7 +41,7 @@ func (w *QuiescenceDetectorWorker) GetName() events.WorkerName {
 }
 
 func (w *QuiescenceDetectorWorker) Run(ctx context.Context) error {
-	ticker := time.NewTicker(w.Config.MetricInterval)
+	ticker := w.robot.Time.NewTicker(w.Config.MetricInterval)
 	defer ticker.Stop()
 	for {
 		select {
@@ -61,8 +61,10 @@ func (w *QuiescenceDetectorWorker) sendQuiescenceDetectorEvent(ctx context.Conte
 		CreatedAt: time.Now().UTC(),
 		Payload: events.QuiescenceDetectorEvent{
 			ID:           ID.ToInt(),
-			LastActivity: events.LastActivity(w.robot.LastUpdatedAt),
+			LastActivity: events.LastActivity(w.robot.Snapshot().LastUpdatedAt),
 		},
+		Vector:  w.robot.VersionVector(),
+		Lamport: w.robot.Clock.Tick(),
 	}:
 	case <-ctx.Done():
 		w.log.Debug("Context done, stopping domainEvent send")
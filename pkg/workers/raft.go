@@ -0,0 +1,167 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"robots/internal/conf"
+	"robots/pkg/events"
+	"robots/pkg/raft"
+	"robots/pkg/robot"
+	"robots/pkg/transport"
+	pb "robots/proto"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// RaftWorker replicates the secret through a small Raft group before it is written.
+// The robots with the lowest IDs form the group, every robot having completed
+// the secret proposes it to the group until told that a secret was committed.
+//
+// The secret is written only once committed by a majority, by the member
+// applying it as leader, and a leader isolated by a partition can't commit
+// anything. Each member applies an entry once: a leader crashing between the
+// commit and the write leaves the committed secret unwritten.
+// Non-members only propose, their node is nil.
+type RaftWorker struct {
+	Config      conf.Config
+	Log         *slog.Logger
+	Name        events.WorkerName
+	Robot       *robot.Robot
+	Node        *raft.Node
+	Members     []robot.ID
+	Transport   transport.Transport
+	Candidacy   <-chan struct{}
+	DomainEvent chan events.Event
+}
+
+func NewRaftWorker(config conf.Config, log *slog.Logger, r *robot.Robot, transport transport.Transport, candidacy <-chan struct{}, domainEvent chan events.Event) RaftWorker {
+	members := raft.Members(config.RaftGroupSize)
+	var node *raft.Node
+	if r.ID.ToInt() < config.RaftGroupSize {
+		rng := rand.New(rand.NewSource(config.Seed + int64(r.ID)))
+		node = raft.New(r.ID, members, config.ElectionTimeout, config.RaftHeartbeat, rng)
+	}
+	return RaftWorker{Config: config, Log: log, Robot: r, Node: node, Members: members, Transport: transport, Candidacy: candidacy, DomainEvent: domainEvent}
+}
+
+func (w RaftWorker) WithName(name string) Worker {
+	w.Name = events.WorkerName(name)
+	return w
+}
+
+func (w RaftWorker) GetName() events.WorkerName {
+	return w.Name
+}
+
+// Run The node isn't safe for concurrent use, the worker is its only caller.
+// A restarted worker keeps the same node, as if its state was persisted.
+func (w RaftWorker) Run(ctx context.Context) error {
+	ticker := w.Robot.Time.NewTicker(w.Config.RaftHeartbeat)
+	defer ticker.Stop()
+	var proposal *pb.RaftEntry
+	var nextProposal time.Time
+	for {
+		select {
+		case <-w.Candidacy:
+			if proposal == nil {
+				proposal = &pb.RaftEntry{ProposerId: int32(w.Robot.ID), Secret: []byte(w.Robot.Snapshot().BuildSecret())}
+				w.propose(proposal)
+				nextProposal = w.Robot.Time.Now().Add(w.Config.ElectionTimeout)
+			}
+		case raftMsg := <-w.Transport.Inbox(w.Robot.ID):
+			var msg pb.RaftMessage
+			if err := proto.Unmarshal(raftMsg, &msg); err != nil {
+				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
+				continue
+			}
+			w.Robot.Clock.Witness(msg.Lamport)
+			if msg.Kind == pb.RaftKind_COMMITTED {
+				if proposal != nil && len(msg.Entries) > 0 {
+					w.Log.Debug(fmt.Sprintf("Robot %d learnt that the secret of robot %d was committed", w.Robot.ID, msg.Entries[0].ProposerId))
+				}
+				proposal = nil
+				continue
+			}
+			if w.Node != nil {
+				w.apply(ctx, w.Node.Handle(&msg, w.Robot.Time.Now()))
+			}
+		case <-ticker.C:
+			now := w.Robot.Time.Now()
+			if w.Node != nil {
+				w.apply(ctx, w.Node.Tick(now))
+			}
+			// Proposals are lost while the group has no leader, they are sent again
+			if proposal != nil && now.After(nextProposal) {
+				w.propose(proposal)
+				nextProposal = now.Add(w.Config.ElectionTimeout)
+			}
+		case <-ctx.Done():
+			w.Log.Debug("Context done, stopping raft")
+			return nil
+		}
+	}
+}
+
+// propose Sends the secret to every member, only the leader appends it
+func (w RaftWorker) propose(proposal *pb.RaftEntry) {
+	for _, member := range w.Members {
+		w.send(member, &pb.RaftMessage{Kind: pb.RaftKind_PROPOSE, SenderId: int32(w.Robot.ID), Entries: []*pb.RaftEntry{proposal}})
+	}
+}
+
+func (w RaftWorker) send(to robot.ID, msg *pb.RaftMessage) {
+	msg.Lamport = w.Robot.Clock.Tick()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+		return
+	}
+	if !w.Transport.Send(w.Robot.ID, to, data) {
+		w.Log.Debug(fmt.Sprintf("Raft message from robot %d to robot %d dropped", w.Robot.ID, to))
+	}
+}
+
+// apply Sends the messages of a step of the node and turns its reports into events
+func (w RaftWorker) apply(ctx context.Context, step raft.Step) {
+	for _, outgoing := range step.Messages {
+		w.send(outgoing.To, outgoing.Message)
+	}
+	for _, report := range step.Reports {
+		switch report.Kind {
+		case raft.Candidacy:
+			w.sendEvent(ctx, events.EventElectionCandidacy, events.ElectionCandidacyEvent{ID: report.Candidate, Term: report.Term})
+		case raft.Vote:
+			w.sendEvent(ctx, events.EventElectionVote, events.ElectionVoteEvent{
+				VoterID: w.Robot.ID, CandidateID: report.Candidate, Term: report.Term, Granted: report.Granted,
+			})
+		case raft.Elected:
+			w.sendEvent(ctx, events.EventRaftLeaderElected, events.RaftLeaderElectedEvent{ID: report.Candidate, Term: report.Term, Votes: report.Votes})
+		case raft.Committed:
+			proposer := robot.ID(report.Entry.ProposerId)
+			w.sendEvent(ctx, events.EventSecretCommitted, events.SecretCommittedEvent{
+				ID: w.Robot.ID, ProposerID: proposer, Index: report.Index, Term: report.Term, Leader: report.Leader,
+			})
+			if report.Leader {
+				w.sendEvent(ctx, events.EventWinnerElected, events.WinnerElectedEvent{ID: proposer.ToInt(), Secret: string(report.Entry.Secret), SecretID: w.Robot.Secret, Term: report.Term})
+			}
+		}
+	}
+}
+
+// sendEvent The outcome of the replication must not be lost, the worker waits for room in the buffer
+func (w RaftWorker) sendEvent(ctx context.Context, eventType events.EventType, payload any) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: eventType,
+		CreatedAt: time.Now().UTC(),
+		Payload:   payload,
+		Vector:    w.Robot.VersionVector(),
+		Lamport:   w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	}
+}
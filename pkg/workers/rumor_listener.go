@@ -0,0 +1,97 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"robots/pkg/events"
+	"robots/pkg/robot"
+	pb "robots/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// RumorListenerWorker receives the rumors pushed by peers.
+// New secret parts are merged (and become hot rumors for this robot too),
+// while parts that were already known are reported back to the sender so
+// it can lose interest in spreading them.
+type RumorListenerWorker struct {
+	Log         *slog.Logger
+	Name        events.WorkerName
+	Robot       *robot.Robot
+	Robots      []*robot.Robot
+	DomainEvent chan events.Event
+}
+
+func NewRumorListenerWorker(log *slog.Logger, robot *robot.Robot, robots []*robot.Robot, domainEvent chan events.Event) RumorListenerWorker {
+	return RumorListenerWorker{Log: log, Robot: robot, Robots: robots, DomainEvent: domainEvent}
+}
+
+func (w RumorListenerWorker) WithName(name string) Worker {
+	w.Name = events.WorkerName(name)
+	return w
+}
+
+func (w RumorListenerWorker) GetName() events.WorkerName {
+	return w.Name
+}
+
+func (w RumorListenerWorker) Run(ctx context.Context) error {
+	for {
+		select {
+		case rumorMsg := <-w.Robot.GossipRumor:
+			var rumor pb.RumorPush
+			if err := proto.Unmarshal(rumorMsg, &rumor); err != nil {
+				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
+				continue
+			}
+			w.Robot.Clock.Witness(rumor.Lamport)
+			secretParts := robot.FromSecretPartsPb(rumor.SecretParts)
+			// Parts are recorded as in transit before being merged
+			w.Robot.ObserveSnapshot(robot.ID(rumor.SenderId), robot.RumorLink, rumor.SnapshotEpoch, secretParts)
+			if rumor.Marker {
+				continue
+			}
+			w.Robot.Termination.Received()
+			var known []int64
+			for _, secretPart := range secretParts {
+				if !w.mergeSecretPart(ctx, secretPart) {
+					known = append(known, int64(secretPart.Index))
+				}
+			}
+			if rumor.SenderId < 0 || int(rumor.SenderId) >= len(w.Robots) {
+				w.Log.Debug(fmt.Sprintf("Robot %d doesn't exist", rumor.SenderId))
+				continue
+			}
+			if len(known) > 0 {
+				w.sendFeedback(w.Robots[rumor.SenderId], known)
+			}
+		case <-ctx.Done():
+			w.Log.Debug("Context done, stopping domainEvent send")
+			return nil
+		}
+	}
+}
+
+// mergeSecretPart Returns true when the part was unknown to the robot
+func (w RumorListenerWorker) mergeSecretPart(ctx context.Context, part robot.SecretPart) (isNew bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			sendInvariantViolationEvent(ctx, w.Robot, w.DomainEvent)
+		}
+	}()
+	return w.Robot.MergeSecretPart(part)
+}
+
+func (w RumorListenerWorker) sendFeedback(sender *robot.Robot, known []int64) {
+	msg, err := proto.Marshal(&pb.RumorFeedback{KnownIndexes: known, SenderId: int32(w.Robot.ID), Lamport: w.Robot.Clock.Tick()})
+	if err != nil {
+		w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+		return
+	}
+	select {
+	case sender.RumorFeedback <- msg:
+	default:
+		w.Log.Debug("RumorFeedback channel is full, dropping message")
+	}
+}
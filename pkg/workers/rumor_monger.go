@@ -0,0 +1,167 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"robots/internal/conf"
+	"robots/pkg/events"
+	"robots/pkg/robot"
+	"robots/pkg/topology"
+	pb "robots/proto"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// RumorMongerWorker spreads the hot rumors of a robot (SIR model).
+// Periodically, it pushes every secret part still "infective" to a random peer.
+// Feedbacks from peers that already knew a part make the robot progressively
+// lose interest in it until the part is "removed" and no longer pushed.
+//
+// Rumor mongering is cheap but probabilistic: some robots may never receive
+// a part (the residue). Anti-entropy can run alongside as a backup.
+type RumorMongerWorker struct {
+	Config      conf.Config
+	Log         *slog.Logger
+	Name        events.WorkerName
+	Robot       *robot.Robot
+	Robots      []*robot.Robot
+	Topology    *topology.Topology
+	DomainEvent chan events.Event
+	Rand        *rand.Rand // Picks the peers and simulates losses, owned by the worker
+}
+
+func NewRumorMongerWorker(config conf.Config, log *slog.Logger, robot *robot.Robot, robots []*robot.Robot, domainEvent chan events.Event) RumorMongerWorker {
+	return RumorMongerWorker{Config: config, Log: log, Robot: robot, Robots: robots, Rand: rand.New(rand.NewSource(time.Now().UnixNano())), DomainEvent: domainEvent}
+}
+
+// WithTopology restricts the peers rumors are pushed to
+func (w RumorMongerWorker) WithTopology(topology *topology.Topology) RumorMongerWorker {
+	w.Topology = topology
+	return w
+}
+
+// WithRand draws the peers from the given rng, derived from the seed of the run
+func (w RumorMongerWorker) WithRand(rng *rand.Rand) RumorMongerWorker {
+	w.Rand = rng
+	return w
+}
+
+func (w RumorMongerWorker) WithName(name string) Worker {
+	w.Name = events.WorkerName(name)
+	return w
+}
+
+func (w RumorMongerWorker) GetName() events.WorkerName {
+	return w.Name
+}
+
+func (w RumorMongerWorker) Run(ctx context.Context) error {
+	if w.Robot.Rumors == nil {
+		w.Log.Info(fmt.Sprintf("Rumor mongering disabled for robot %d", w.Robot.ID))
+		return nil
+	}
+	ticker := w.Robot.Time.NewTicker(w.Config.RumorTime)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.pushRumors(ctx)
+		case feedbackMsg := <-w.Robot.RumorFeedback:
+			var feedback pb.RumorFeedback
+			if err := proto.Unmarshal(feedbackMsg, &feedback); err != nil {
+				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
+				continue
+			}
+			w.Robot.Clock.Witness(feedback.Lamport)
+			for _, index := range feedback.KnownIndexes {
+				if w.Robot.Rumors.AlreadyKnown(int(index)) {
+					w.sendRumorRemovedEvent(ctx, int(index))
+				}
+			}
+		case <-ctx.Done():
+			w.Log.Debug("Context done, stopping domainEvent send")
+			return nil
+		}
+	}
+}
+
+func (w RumorMongerWorker) pushRumors(ctx context.Context) {
+	hot := w.Robot.Rumors.Hot()
+	if len(hot) == 0 {
+		return
+	}
+	receiver := w.choosePeer()
+	if receiver == nil {
+		return
+	}
+	if isSimulated(w.Rand, w.Config.PercentageOfLost) {
+		return
+	}
+	secretParts := w.Robot.GetSecretParts(hot)
+	// In the outbox, so the snapshot epoch of the rumor can't be overtaken
+	w.Robot.Outbox(func() {
+		lamport := w.Robot.Clock.Tick()
+		msg, err := proto.Marshal(&pb.RumorPush{SecretParts: robot.ToSecretPartsPb(secretParts), SenderId: int32(w.Robot.ID), Lamport: lamport, SnapshotEpoch: w.Robot.SnapshotEpoch()})
+		if err != nil {
+			w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+			return
+		}
+		w.Robot.Termination.Sent()
+		select {
+		case receiver.GossipRumor <- msg:
+			w.sendMessageSentEvent(ctx, len(msg), lamport)
+		case <-ctx.Done():
+			w.Robot.Termination.Dropped()
+			w.Log.Debug("Context done, stopping domainEvent send")
+		default:
+			w.Robot.Termination.Dropped()
+			w.Log.Debug("GossipRumor channel is full, dropping message")
+		}
+	})
+}
+
+func (w RumorMongerWorker) choosePeer() *robot.Robot {
+	var peers []*robot.Robot
+	if w.Topology == nil {
+		peers = robot.ChooseRobots(w.Robot, w.Robots, 1, w.Rand)
+	} else {
+		peers = w.Topology.ChoosePeers(w.Robot, w.Robots, 1, w.Rand)
+	}
+	if len(peers) == 0 {
+		return nil
+	}
+	return peers[0]
+}
+
+func (w RumorMongerWorker) sendMessageSentEvent(ctx context.Context, size int, lamport uint64) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventMessageSent,
+		CreatedAt: time.Now().UTC(),
+		Payload:   events.MessageSentEvent{SenderID: w.Robot.ID, Kind: events.MessageRumor, Bytes: size},
+		Lamport:   lamport,
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	default:
+		w.Log.Debug(fmt.Sprintf("[%s] Buffer is full", w.Name))
+	}
+}
+
+func (w RumorMongerWorker) sendRumorRemovedEvent(ctx context.Context, index int) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventRumorRemoved,
+		CreatedAt: time.Now().UTC(),
+		Payload:   events.RumorRemovedEvent{ID: w.Robot.ID, Index: index},
+		Lamport:   w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	default:
+		w.Log.Debug(fmt.Sprintf("[%s] Buffer is full", w.Name))
+	}
+}
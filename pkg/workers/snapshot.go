@@ -0,0 +1,171 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"robots/internal/conf"
+	"robots/pkg/events"
+	"robots/pkg/robot"
+	"robots/pkg/snapshots"
+	pb "robots/proto"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// SnapshotWorker takes part in the Chandy-Lamport global snapshots for one robot.
+// Robot 0 is the initiator: it records its state every snapshot interval, or
+// when a snapshot is requested (SIGUSR1), then every robot sends markers on
+// its outgoing links once it recorded its own state.
+//
+// Markers share the summary, update and rumor channels with the gossip, and
+// are sent in the robot's outbox like every other message, so they can't
+// overtake a message sent before them. A full channel delays the marker to
+// the next round instead of dropping it, the snapshot would never complete.
+// Merkle exchanges only carry hashes: the parts they find missing are sent
+// as updates, which are recorded.
+type SnapshotWorker struct {
+	Config      conf.Config
+	Log         *slog.Logger
+	Name        events.WorkerName
+	Robot       *robot.Robot
+	Robots      []*robot.Robot
+	Collector   *snapshots.Collector
+	DomainEvent chan events.Event
+}
+
+func NewSnapshotWorker(config conf.Config, log *slog.Logger, robot *robot.Robot, robots []*robot.Robot, collector *snapshots.Collector, domainEvent chan events.Event) SnapshotWorker {
+	return SnapshotWorker{Config: config, Log: log, Robot: robot, Robots: robots, Collector: collector, DomainEvent: domainEvent}
+}
+
+func (w SnapshotWorker) WithName(name string) Worker {
+	w.Name = events.WorkerName(name)
+	return w
+}
+
+func (w SnapshotWorker) GetName() events.WorkerName {
+	return w.Name
+}
+
+func (w SnapshotWorker) Run(ctx context.Context) error {
+	ticker := w.Robot.Time.NewTicker(w.Config.GossipTime)
+	defer ticker.Stop()
+	var interval <-chan time.Time
+	var requests <-chan struct{}
+	if w.Robot.ID == 0 {
+		requests = w.Collector.Requests()
+		if w.Config.SnapshotInterval > 0 {
+			snapshotTicker := time.NewTicker(w.Config.SnapshotInterval)
+			defer snapshotTicker.Stop()
+			interval = snapshotTicker.C
+		}
+	}
+	for {
+		select {
+		case <-ticker.C:
+			w.sendMarkers()
+			w.collect(ctx)
+		case <-interval:
+			w.start()
+		case <-requests:
+			w.start()
+		case <-ctx.Done():
+			w.Log.Debug("Context done, stopping snapshot worker")
+			return nil
+		}
+	}
+}
+
+// start Records the state of the initiator, unless the previous snapshot isn't complete yet
+func (w SnapshotWorker) start() {
+	id, ok := w.Collector.Begin()
+	if !ok {
+		w.Log.Debug("A global snapshot is still in progress")
+		return
+	}
+	w.Log.Debug(fmt.Sprintf("Robot %d starts global snapshot %d", w.Robot.ID, id))
+	w.Robot.StartSnapshot(id)
+	w.sendMarkers()
+}
+
+func (w SnapshotWorker) sendMarkers() {
+	w.Robot.Outbox(func() {
+		epoch, markers := w.Robot.PendingMarkers()
+		var delayed []robot.PendingMarker
+		for _, marker := range markers {
+			msg, err := w.buildMarker(marker.Kind, epoch)
+			if err != nil {
+				w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+				continue
+			}
+			select {
+			case w.channel(marker) <- msg:
+			default:
+				delayed = append(delayed, marker)
+			}
+		}
+		if len(delayed) > 0 {
+			w.Robot.RequeueMarkers(epoch, delayed)
+		}
+	})
+}
+
+// channel Incoming channel of the peer for the link closed by the marker
+func (w SnapshotWorker) channel(marker robot.PendingMarker) chan []byte {
+	switch marker.Kind {
+	case robot.UpdateLink:
+		return w.Robots[marker.To].GossipUpdate
+	case robot.RumorLink:
+		return w.Robots[marker.To].GossipRumor
+	default:
+		return w.Robots[marker.To].GossipSummary
+	}
+}
+
+func (w SnapshotWorker) buildMarker(kind robot.LinkKind, epoch uint64) ([]byte, error) {
+	lamport := w.Robot.Clock.Tick()
+	switch kind {
+	case robot.UpdateLink:
+		return proto.Marshal(&pb.GossipUpdate{SenderId: int32(w.Robot.ID), Lamport: lamport, SnapshotEpoch: epoch, Marker: true})
+	case robot.RumorLink:
+		return proto.Marshal(&pb.RumorPush{SenderId: int32(w.Robot.ID), Lamport: lamport, SnapshotEpoch: epoch, Marker: true})
+	default:
+		return proto.Marshal(&pb.GossipSummary{SenderId: int32(w.Robot.ID), Lamport: lamport, SnapshotEpoch: epoch, Marker: true})
+	}
+}
+
+// collect Hands the completed local snapshots to the collector and saves the global one
+func (w SnapshotWorker) collect(ctx context.Context) {
+	for _, local := range w.Robot.CompletedSnapshots() {
+		global, ok := w.Collector.Add(local)
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf(w.Config.SnapshotFile, global.ID)
+		if err := snapshots.Write(path, global); err != nil {
+			w.Log.Error(fmt.Sprintf("Unable to write global snapshot %d : %s", global.ID, err.Error()))
+			continue
+		}
+		inFlight := 0
+		for _, local := range global.Robots {
+			inFlight += len(local.InFlight)
+		}
+		w.sendGlobalSnapshotEvent(ctx, global.ID, path, inFlight, global.Violations)
+	}
+}
+
+func (w SnapshotWorker) sendGlobalSnapshotEvent(ctx context.Context, id uint64, path string, inFlight int, violations []string) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventGlobalSnapshot,
+		CreatedAt: time.Now().UTC(),
+		Payload:   events.GlobalSnapshotEvent{SnapshotID: id, Path: path, InFlight: inFlight, Violations: violations},
+		Lamport:   w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	default:
+		w.Log.Debug(fmt.Sprintf("[%s] Buffer is full", w.Name))
+	}
+}
@@ -4,16 +4,31 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"math/rand"
 	"robots/internal/conf"
+	"robots/pkg/errors"
 	"robots/pkg/events"
 	"robots/pkg/robot"
+	"robots/pkg/topology"
 	pb "robots/proto"
+	"strconv"
 	"time"
 
+	"github.com/samber/lo"
 	"google.golang.org/protobuf/proto"
 )
 
+// AdaptiveFanout lets a robot contact more peers while it still misses many words
+const AdaptiveFanout = "adaptive"
+
+var gossipModes = map[string]pb.GossipMode{
+	"":          pb.GossipMode_PULL,
+	"pull":      pb.GossipMode_PULL,
+	"push":      pb.GossipMode_PUSH,
+	"push-pull": pb.GossipMode_PUSH_PULL,
+}
+
 // StartGossipWorker initiates gossip rounds by periodically selecting peers
 // and emitting state exchange messages.
 //
@@ -24,16 +39,43 @@ import (
 // This worker does not apply state mutations directly and does not make
 // convergence decisions. It only drives communication activity.
 type StartGossipWorker struct {
-	Config      conf.Config
-	Log         *slog.Logger
-	Name        events.WorkerName
-	Robot       *robot.Robot
-	Robots      []*robot.Robot
-	DomainEvent chan events.Event
+	Config         conf.Config
+	Log            *slog.Logger
+	Name           events.WorkerName
+	Robot          *robot.Robot
+	Robots         []*robot.Robot
+	Topology       *topology.Topology
+	DomainEvent    chan events.Event
+	Rand           *rand.Rand           // Picks the peers and simulates losses, owned by the worker
+	Secrets        robot.Secrets        // Other secrets summarized in the same messages
+	Mode           pb.GossipMode        // Parsed once by the constructor, main validated the config
+	Reconciliation robot.Reconciliation // Merkle roots replace the summaries when enabled
+}
+
+func NewStartGossipWorker(config conf.Config, log *slog.Logger, r *robot.Robot, robots []*robot.Robot, DomainEvent chan events.Event) StartGossipWorker {
+	mode, _ := ParseGossipMode(config.GossipMode)
+	reconciliation, _ := robot.ParseReconciliation(config.Reconciliation)
+	return StartGossipWorker{Config: config, Log: log, Robot: r, Robots: robots, Rand: rand.New(rand.NewSource(time.Now().UnixNano())), DomainEvent: DomainEvent, Mode: mode, Reconciliation: reconciliation}
+}
+
+// WithTopology restricts the peers this worker gossips with.
+// Without topology, any other robot can be chosen (complete graph).
+func (w StartGossipWorker) WithTopology(topology *topology.Topology) StartGossipWorker {
+	w.Topology = topology
+	return w
+}
+
+// WithSecrets makes every summary also cover the other secrets disseminated at once,
+// so they share the same messages and channels.
+func (w StartGossipWorker) WithSecrets(secrets robot.Secrets) StartGossipWorker {
+	w.Secrets = secrets
+	return w
 }
 
-func NewStartGossipWorker(config conf.Config, log *slog.Logger, robot *robot.Robot, robots []*robot.Robot, DomainEvent chan events.Event) StartGossipWorker {
-	return StartGossipWorker{Config: config, Log: log, Robot: robot, Robots: robots, DomainEvent: DomainEvent}
+// WithRand draws the peers from the given rng, derived from the seed of the run
+func (w StartGossipWorker) WithRand(rng *rand.Rand) StartGossipWorker {
+	w.Rand = rng
+	return w
 }
 
 func (w StartGossipWorker) WithName(name string) Worker {
@@ -46,14 +88,37 @@ func (w StartGossipWorker) GetName() events.WorkerName {
 }
 
 func (w StartGossipWorker) Run(ctx context.Context) error {
-	ticker := time.NewTicker(w.Config.GossipTime)
+	ticker := w.Robot.Time.NewTicker(w.Config.GossipTime)
 	defer ticker.Stop()
+	version, err := robot.ParseSummaryVersion(w.Config.SummaryEncoding)
+	if err != nil {
+		w.Log.Info(err.Error())
+		return nil
+	}
+	fallback := bloomFallback{rounds: w.Config.BloomFallbackRounds}
 	for {
 		select {
 		case <-ticker.C:
 			sender := w.Robot
-			receiver := robot.ChooseRobot(sender, w.Robots)
-			w.ExchangeMessage(ctx, sender, receiver)
+			receivers := w.choosePeers(sender, w.fanout(sender))
+			if len(receivers) == 0 {
+				w.Log.Debug(fmt.Sprintf("Robot %d has no neighbor to gossip with", sender.ID))
+				continue
+			}
+			w.sendGossipRoundEvent(ctx, sender, receivers)
+			roundVersion := version
+			if version == pb.SummaryVersion_BLOOM {
+				snapshot := sender.Snapshot()
+				exact := fallback.stalled(snapshot.Known(), snapshot.Missing(w.Config.EndOfSecret))
+				if exact {
+					// False positives may hide parts forever, an exact summary recovers them
+					roundVersion = pb.SummaryVersion_RANGES
+				}
+				w.sendBloomSummaryEvent(ctx, sender, exact)
+			}
+			for _, receiver := range receivers {
+				w.exchangeMessage(ctx, sender, receiver, roundVersion)
+			}
 		case <-ctx.Done():
 			w.Log.Debug("Context done, stopping domainEvent send")
 			return nil
@@ -61,56 +126,265 @@ func (w StartGossipWorker) Run(ctx context.Context) error {
 	}
 }
 
+func (w StartGossipWorker) choosePeers(sender *robot.Robot, k int) []*robot.Robot {
+	if w.Topology == nil {
+		return robot.ChooseRobots(sender, w.Robots, k, w.Rand)
+	}
+	return w.Topology.ChoosePeers(sender, w.Robots, k, w.Rand)
+}
+
+// fanout Number of distinct peers contacted during a round.
+// In adaptive mode, it grows with the share of the secret still missing,
+// from 1 (nothing missing) up to GossipMaxFanout (nothing known).
+func (w StartGossipWorker) fanout(sender *robot.Robot) int {
+	k, adaptive, err := ParseFanout(w.Config.GossipFanout)
+	if err != nil {
+		return 1
+	}
+	if !adaptive {
+		return k
+	}
+	snapshot := sender.Snapshot()
+	missing := snapshot.Missing(w.Config.EndOfSecret)
+	known := snapshot.Known()
+	ratio := float64(missing) / float64(max(1, missing+known))
+	return 1 + int(math.Round(ratio*float64(max(0, w.Config.GossipMaxFanout-1))))
+}
+
+// ParseFanout Reads a fanout setting: either a fixed number of peers or "adaptive".
+// An empty value keeps the historical behavior of one peer per round.
+func ParseFanout(value string) (int, bool, error) {
+	if value == "" {
+		return 1, false, nil
+	}
+	if value == AdaptiveFanout {
+		return 0, true, nil
+	}
+	k, err := strconv.Atoi(value)
+	if err != nil || k <= 0 {
+		return 0, false, errors.ErrInvalidGossipFanout
+	}
+	return k, false, nil
+}
+
+// ParseGossipMode Reads the gossip mode setting (pull by default)
+func ParseGossipMode(value string) (pb.GossipMode, error) {
+	mode, ok := gossipModes[value]
+	if !ok {
+		return pb.GossipMode_PULL, errors.ErrUnknownGossipMode
+	}
+	return mode, nil
+}
+
+// bloomFallback Detects when bloom summaries stop bringing new parts.
+// A false positive makes the peer believe we hold a part, so it is never sent:
+// after some rounds without progress, an exact summary is sent instead.
+type bloomFallback struct {
+	rounds        int
+	lastKnown     int
+	stalledRounds int
+}
+
+func (b *bloomFallback) stalled(known, missing int) bool {
+	if known != b.lastKnown || missing == 0 {
+		b.lastKnown = known
+		b.stalledRounds = 0
+		return false
+	}
+	b.stalledRounds++
+	if b.rounds > 0 && b.stalledRounds >= b.rounds {
+		b.stalledRounds = 0
+		return true
+	}
+	return false
+}
+
 // ExchangeMessage r1 send a message to r2
 // Simulate lost and duplicated messages
 func (w StartGossipWorker) ExchangeMessage(ctx context.Context, sender, receiver *robot.Robot) {
+	version, err := robot.ParseSummaryVersion(w.Config.SummaryEncoding)
+	if err != nil {
+		w.Log.Info(err.Error())
+		return
+	}
+	w.exchangeMessage(ctx, sender, receiver, version)
+}
+
+func (w StartGossipWorker) exchangeMessage(ctx context.Context, sender, receiver *robot.Robot, version pb.SummaryVersion) {
 	if sender.ID == receiver.ID {
 		return
 	}
 	for i := 0; i < w.Config.MaxAttempts; i++ {
-		// Calculate and simulate a random percentage
-		isSimulated := func(percentage int) bool {
-			return rand.Float32() < float32(percentage)/100.0
-		}
-
 		// Percentage of lost messages
-		if isSimulated(w.Config.PercentageOfLost) {
+		if isSimulated(w.Rand, w.Config.PercentageOfLost) {
 			continue
 		}
 
 		// Percentage of duplicated messages
 		var times int
-		if isSimulated(w.Config.PercentageOfDuplicated) {
+		if isSimulated(w.Rand, w.Config.PercentageOfDuplicated) {
 			times = w.Config.DuplicatedNumber
 		}
 
 		for j := 0; j <= times; j++ {
-			// Sender sends his own indexes to receiver
-			gossipSender := pb.GossipSummary{Indexes: sender.Indexes(), SenderId: int32(sender.ID)}
-			msgSender, err := proto.Marshal(&gossipSender)
-			if err != nil {
-				w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
-				continue
-			}
-			select {
-			case receiver.GossipSummary <- msgSender:
-				w.sendMessageSentEvent(ctx, sender)
-			case <-ctx.Done():
-				w.Log.Debug("Context done, stopping domainEvent send")
+			if !w.send(ctx, sender, receiver, version) {
 				return
-			default:
-				w.Log.Debug("StartGossip channel is full, dropping message")
 			}
 		}
 	}
 }
 
-func (w StartGossipWorker) sendMessageSentEvent(ctx context.Context, sender *robot.Robot) {
+// send Builds and sends a single message in the sender's outbox, so its snapshot epoch can't be overtaken.
+// Returns false once the context is done.
+func (w StartGossipWorker) send(ctx context.Context, sender, receiver *robot.Robot, version pb.SummaryVersion) bool {
+	done := false
+	sender.Outbox(func() {
+		// One Lamport tick per send, stamped on both the message and its MessageSent event
+		lamport := sender.Clock.Tick()
+		msgSender, channel, kind, err := w.openRound(sender, receiver, version, lamport)
+		if err != nil {
+			w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+			return
+		}
+		if msgSender == nil {
+			// Nothing the receiver is likely to lack
+			return
+		}
+		// Only updates carry parts, summaries are control messages
+		basic := kind == events.MessageUpdate
+		if basic {
+			sender.Termination.Sent()
+		}
+		select {
+		case channel <- msgSender:
+			w.sendMessageSentEvent(ctx, sender, kind, len(msgSender), lamport)
+		case <-ctx.Done():
+			if basic {
+				sender.Termination.Dropped()
+			}
+			w.Log.Debug("Context done, stopping domainEvent send")
+			done = true
+		default:
+			if basic {
+				sender.Termination.Dropped()
+			}
+			w.Log.Debug("StartGossip channel is full, dropping message")
+		}
+	})
+	return !done
+}
+
+// isSimulated Calculate and simulate a random percentage, drawn from the rng of the worker
+func isSimulated(rng *rand.Rand, percentage int) bool {
+	return rng.Float32() < float32(percentage)/100.0
+}
+
+// openRound Builds either the root of a Merkle reconciliation or a gossip message, never both
+func (w StartGossipWorker) openRound(sender, receiver *robot.Robot, version pb.SummaryVersion, lamport uint64) ([]byte, chan []byte, events.MessageKind, error) {
+	if w.Reconciliation == robot.MerkleReconciliation {
+		return w.buildMerkleMessage(sender, receiver, lamport)
+	}
+	return w.buildMessage(sender, receiver, w.Mode, version, lamport)
+}
+
+// buildMessage Prepares the message opening a round with the receiver:
+// - pull and push-pull: the sender sends his own indexes as a summary, with those of its other secrets
+// - push: the sender directly sends the words the receiver likely lacks
+func (w StartGossipWorker) buildMessage(sender, receiver *robot.Robot, mode pb.GossipMode, version pb.SummaryVersion, lamport uint64) ([]byte, chan []byte, events.MessageKind, error) {
+	if mode == pb.GossipMode_PUSH {
+		secretParts := sender.GetWordsToPush(receiver.ID)
+		if len(secretParts) == 0 {
+			return nil, nil, events.MessageUpdate, nil
+		}
+		gossipUpdate := pb.GossipUpdate{SecretParts: robot.ToSecretPartsPb(secretParts), SenderId: int32(sender.ID), Lamport: lamport, SnapshotEpoch: sender.SnapshotEpoch(), SecretId: string(sender.Secret)}
+		msg, err := proto.Marshal(&gossipUpdate)
+		return msg, receiver.GossipUpdate, events.MessageUpdate, err
+	}
+	gossipSummary := sender.Summary(version, w.Config.BloomFalsePositiveRate)
+	gossipSummary.Mode = mode
+	gossipSummary.Lamport = lamport
+	for _, id := range w.Secrets.IDs() {
+		other, ok := w.Secrets.Robot(id, sender.ID)
+		if !ok || id == sender.Secret {
+			continue
+		}
+		summary := other.Summary(version, w.Config.BloomFalsePositiveRate)
+		summary.Mode = mode
+		summary.Lamport = lamport
+		gossipSummary.Others = append(gossipSummary.Others, summary)
+	}
+	msg, err := proto.Marshal(gossipSummary)
+	return msg, receiver.GossipSummary, events.MessageSummary, err
+}
+
+// buildMerkleMessage Opens a Merkle reconciliation by sending the root of the sender's tree
+func (w StartGossipWorker) buildMerkleMessage(sender, receiver *robot.Robot, lamport uint64) ([]byte, chan []byte, events.MessageKind, error) {
+	exchange := pb.MerkleExchange{
+		SenderId:   int32(sender.ID),
+		ExchangeId: w.Rand.Uint64(),
+		Leaves:     uint32(sender.MerkleLeaves()),
+		Nodes:      sender.MerkleNodes([]int{1}),
+		Lamport:    lamport,
+	}
+	msg, err := proto.Marshal(&exchange)
+	return msg, receiver.GossipMerkle, events.MessageMerkle, err
+}
+
+func (w StartGossipWorker) sendGossipRoundEvent(ctx context.Context, sender *robot.Robot, receivers []*robot.Robot) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventGossipRound,
+		CreatedAt: time.Now().UTC(),
+		Payload: events.GossipRoundEvent{
+			SenderID: sender.ID,
+			Fanout:   len(receivers),
+			PeerIDs: lo.Map(receivers, func(item *robot.Robot, _ int) robot.ID {
+				return item.ID
+			}),
+		},
+		Lamport: sender.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	default:
+		w.Log.Debug(fmt.Sprintf("[%s] Buffer is full", w.Name))
+	}
+}
+
+func (w StartGossipWorker) sendBloomSummaryEvent(ctx context.Context, sender *robot.Robot, exact bool) {
+	items := sender.Snapshot().Known()
+	filter := robot.NewBloomFilter(items, w.Config.BloomFalsePositiveRate)
+	filter.Items = uint32(items)
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventBloomSummary,
+		CreatedAt: time.Now().UTC(),
+		Payload: events.BloomSummaryEvent{
+			SenderID:               sender.ID,
+			Items:                  items,
+			Bits:                   len(filter.Bits) * 8,
+			Hashes:                 int(filter.Hashes),
+			TargetFalsePositive:    w.Config.BloomFalsePositiveRate,
+			EstimatedFalsePositive: filter.FalsePositiveRate(),
+			ExactFallback:          exact,
+		},
+		Lamport: sender.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	default:
+		w.Log.Debug(fmt.Sprintf("[%s] Buffer is full", w.Name))
+	}
+}
+
+func (w StartGossipWorker) sendMessageSentEvent(ctx context.Context, sender *robot.Robot, kind events.MessageKind, size int, lamport uint64) {
 	select {
 	case w.DomainEvent <- events.Event{
 		EventType: events.EventMessageSent,
 		CreatedAt: time.Now().UTC(),
-		Payload:   events.MessageSentEvent{SenderID: sender.ID},
+		Payload:   events.MessageSentEvent{SenderID: sender.ID, Kind: kind, Bytes: size},
+		Vector:    sender.VersionVector(),
+		Lamport:   lamport,
 	}:
 	case <-ctx.Done():
 		w.Log.Debug("Context done, stopping domainEvent send")
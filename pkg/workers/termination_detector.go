@@ -0,0 +1,168 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"robots/internal/conf"
+	"robots/pkg/events"
+	"robots/pkg/robot"
+	pb "robots/proto"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TerminationDetectorWorker runs the Dijkstra-Safra termination detection for one robot.
+// Robots form a ring ordered by ID and robot 0 is the initiator. A robot keeps
+// the token while it is active (the secret is not complete) and forwards it
+// once passive, adding its balance of basic messages and its color.
+//
+// When the token comes back white with a zero balance, no robot is active and
+// no part is in transit: the initiator announces the global termination, and
+// elects itself as winner since it holds the whole secret. Otherwise a new
+// wave starts. Unlike the quiet period, no timing assumption is involved.
+// In push mode, robots keep pushing parts to each other, so the system never
+// becomes quiescent and the detection rightly never concludes.
+type TerminationDetectorWorker struct {
+	Config      conf.Config
+	Log         *slog.Logger
+	Name        events.WorkerName
+	Robot       *robot.Robot
+	Robots      []*robot.Robot
+	DomainEvent chan events.Event
+	Candidacy   chan<- struct{}
+}
+
+func NewTerminationDetectorWorker(config conf.Config, log *slog.Logger, robot *robot.Robot, robots []*robot.Robot, domainEvent chan events.Event) TerminationDetectorWorker {
+	return TerminationDetectorWorker{Config: config, Log: log, Robot: robot, Robots: robots, DomainEvent: domainEvent}
+}
+
+// WithCandidacy makes the initiator run for the election on termination, rather than writing itself
+func (w TerminationDetectorWorker) WithCandidacy(candidacy chan<- struct{}) TerminationDetectorWorker {
+	w.Candidacy = candidacy
+	return w
+}
+
+func (w TerminationDetectorWorker) WithName(name string) Worker {
+	w.Name = events.WorkerName(name)
+	return w
+}
+
+func (w TerminationDetectorWorker) GetName() events.WorkerName {
+	return w.Name
+}
+
+func (w TerminationDetectorWorker) Run(ctx context.Context) error {
+	ticker := w.Robot.Time.NewTicker(w.Config.GossipTime)
+	defer ticker.Stop()
+	initiator := w.Robot.ID == 0
+	var held *pb.TerminationToken
+	var wave uint64
+	inProgress := false
+	for {
+		select {
+		case <-ticker.C:
+			if !w.isPassive() {
+				continue
+			}
+			if initiator && !inProgress {
+				wave++
+				inProgress = true
+				w.Robot.Termination.Whiten()
+				w.forward(ctx, &pb.TerminationToken{Wave: wave})
+				continue
+			}
+			if held != nil {
+				w.pass(ctx, held)
+				held = nil
+			}
+		case tokenMsg := <-w.Robot.TerminationToken:
+			var token pb.TerminationToken
+			if err := proto.Unmarshal(tokenMsg, &token); err != nil {
+				w.Log.Info(fmt.Sprintf("Unable to decode proto message : %s", err.Error()))
+				continue
+			}
+			w.Robot.Clock.Witness(token.Lamport)
+			if !initiator {
+				held = &token
+				if w.isPassive() {
+					w.pass(ctx, held)
+					held = nil
+				}
+				continue
+			}
+			// The initiator started the wave once passive, and stays passive
+			if w.Robot.Termination.Terminated(token.Count, token.Black) {
+				w.sendGlobalTerminationEvent(ctx, token.Wave)
+				if w.Candidacy != nil {
+					runForElection(w.Candidacy)
+					return nil
+				}
+				w.sendWinnerElectedEvent(ctx)
+				return nil
+			}
+			w.Log.Debug(fmt.Sprintf("Termination wave %d failed (count %d, black %t)", token.Wave, token.Count, token.Black))
+			inProgress = false
+		case <-ctx.Done():
+			w.Log.Debug("Context done, stopping domainEvent send")
+			return nil
+		}
+	}
+}
+
+func (w TerminationDetectorWorker) isPassive() bool {
+	return w.Robot.Snapshot().IsSecretCompleted(w.Config.EndOfSecret)
+}
+
+// pass Adds the robot's counter and color to the token before forwarding it
+func (w TerminationDetectorWorker) pass(ctx context.Context, token *pb.TerminationToken) {
+	token.Count, token.Black = w.Robot.Termination.Forward(token.Count, token.Black)
+	w.forward(ctx, token)
+}
+
+// forward Sends the token to the next robot of the ring.
+// The token is never dropped: losing it would block the detection forever.
+func (w TerminationDetectorWorker) forward(ctx context.Context, token *pb.TerminationToken) {
+	next := w.Robots[(w.Robot.ID.ToInt()+1)%len(w.Robots)]
+	token.SenderId = int32(w.Robot.ID)
+	token.Lamport = w.Robot.Clock.Tick()
+	msg, err := proto.Marshal(token)
+	if err != nil {
+		w.Log.Info(fmt.Sprintf("Unable to encode proto message : %s", err.Error()))
+		return
+	}
+	select {
+	case next.TerminationToken <- msg:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	}
+}
+
+func (w TerminationDetectorWorker) sendGlobalTerminationEvent(ctx context.Context, wave uint64) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventGlobalTermination,
+		CreatedAt: time.Now().UTC(),
+		Payload:   events.GlobalTerminationEvent{InitiatorID: w.Robot.ID, Waves: int(wave)},
+		Vector:    w.Robot.VersionVector(),
+		Lamport:   w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	}
+}
+
+func (w TerminationDetectorWorker) sendWinnerElectedEvent(ctx context.Context) {
+	select {
+	case w.DomainEvent <- events.Event{
+		EventType: events.EventWinnerElected,
+		CreatedAt: time.Now().UTC(),
+		Payload:   events.WinnerElectedEvent{ID: w.Robot.ID.ToInt(), SecretID: w.Robot.Secret},
+		Vector:    w.Robot.VersionVector(),
+		Lamport:   w.Robot.Clock.Tick(),
+	}:
+	case <-ctx.Done():
+		w.Log.Debug("Context done, stopping domainEvent send")
+	}
+}
@@ -20,10 +20,240 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// How the peer of a gossip round is expected to react to a summary
+type GossipMode int32
+
+const (
+	GossipMode_PULL      GossipMode = 0 // The peer responds with the parts the initiator is missing
+	GossipMode_PUSH      GossipMode = 1 // The initiator directly sends the parts the peer likely lacks
+	GossipMode_PUSH_PULL GossipMode = 2 // The peer responds with the missing parts and its own summary
+)
+
+// Enum value maps for GossipMode.
+var (
+	GossipMode_name = map[int32]string{
+		0: "PULL",
+		1: "PUSH",
+		2: "PUSH_PULL",
+	}
+	GossipMode_value = map[string]int32{
+		"PULL":      0,
+		"PUSH":      1,
+		"PUSH_PULL": 2,
+	}
+)
+
+func (x GossipMode) Enum() *GossipMode {
+	p := new(GossipMode)
+	*p = x
+	return p
+}
+
+func (x GossipMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GossipMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_robot_proto_enumTypes[0].Descriptor()
+}
+
+func (GossipMode) Type() protoreflect.EnumType {
+	return &file_proto_robot_proto_enumTypes[0]
+}
+
+func (x GossipMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GossipMode.Descriptor instead.
+func (GossipMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{0}
+}
+
+// How the indexes of a summary are encoded
+type SummaryVersion int32
+
+const (
+	SummaryVersion_INDEX_LIST SummaryVersion = 0 // Every held index is listed in indexes
+	SummaryVersion_RANGES     SummaryVersion = 1 // Sorted intervals of consecutive held indexes in ranges
+	SummaryVersion_BITMAP     SummaryVersion = 2 // One bit per index in bitmap, the lowest bit of the first byte is index 0
+	SummaryVersion_BLOOM      SummaryVersion = 3 // Bloom filter of the held (index, hash(word)) pairs in bloom
+	SummaryVersion_COMPACT    SummaryVersion = 4 // Never sent: each summary is encoded with the smallest of the exact versions above
+)
+
+// Enum value maps for SummaryVersion.
+var (
+	SummaryVersion_name = map[int32]string{
+		0: "INDEX_LIST",
+		1: "RANGES",
+		2: "BITMAP",
+		3: "BLOOM",
+		4: "COMPACT",
+	}
+	SummaryVersion_value = map[string]int32{
+		"INDEX_LIST": 0,
+		"RANGES":     1,
+		"BITMAP":     2,
+		"BLOOM":      3,
+		"COMPACT":    4,
+	}
+)
+
+func (x SummaryVersion) Enum() *SummaryVersion {
+	p := new(SummaryVersion)
+	*p = x
+	return p
+}
+
+func (x SummaryVersion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SummaryVersion) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_robot_proto_enumTypes[1].Descriptor()
+}
+
+func (SummaryVersion) Type() protoreflect.EnumType {
+	return &file_proto_robot_proto_enumTypes[1]
+}
+
+func (x SummaryVersion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SummaryVersion.Descriptor instead.
+func (SummaryVersion) EnumDescriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{1}
+}
+
+// Messages of the leader election choosing the robot writing the secret
+type ElectionKind int32
+
+const (
+	ElectionKind_ELECTION      ElectionKind = 0 // Bully: a candidate challenges the robots with a higher ID
+	ElectionKind_ANSWER        ElectionKind = 1 // Bully: a higher robot takes over the election
+	ElectionKind_COORDINATOR   ElectionKind = 2 // The sender was elected and writes the secret
+	ElectionKind_LEASE_REQUEST ElectionKind = 3 // Lease: a candidate asks for the lease of the term
+	ElectionKind_LEASE_GRANT   ElectionKind = 4 // Lease: the voter grants its lease to the candidate
+	ElectionKind_LEASE_REJECT  ElectionKind = 5 // Lease: the voter's lease is held by another candidate
+	ElectionKind_LEASE_RENEW   ElectionKind = 6 // Lease: the leader renews its lease for a new term
+)
+
+// Enum value maps for ElectionKind.
+var (
+	ElectionKind_name = map[int32]string{
+		0: "ELECTION",
+		1: "ANSWER",
+		2: "COORDINATOR",
+		3: "LEASE_REQUEST",
+		4: "LEASE_GRANT",
+		5: "LEASE_REJECT",
+		6: "LEASE_RENEW",
+	}
+	ElectionKind_value = map[string]int32{
+		"ELECTION":      0,
+		"ANSWER":        1,
+		"COORDINATOR":   2,
+		"LEASE_REQUEST": 3,
+		"LEASE_GRANT":   4,
+		"LEASE_REJECT":  5,
+		"LEASE_RENEW":   6,
+	}
+)
+
+func (x ElectionKind) Enum() *ElectionKind {
+	p := new(ElectionKind)
+	*p = x
+	return p
+}
+
+func (x ElectionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ElectionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_robot_proto_enumTypes[2].Descriptor()
+}
+
+func (ElectionKind) Type() protoreflect.EnumType {
+	return &file_proto_robot_proto_enumTypes[2]
+}
+
+func (x ElectionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ElectionKind.Descriptor instead.
+func (ElectionKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{2}
+}
+
+// Messages of the Raft group replicating the secret before it is written
+type RaftKind int32
+
+const (
+	RaftKind_REQUEST_VOTE   RaftKind = 0
+	RaftKind_VOTE           RaftKind = 1
+	RaftKind_APPEND_ENTRIES RaftKind = 2
+	RaftKind_APPEND_RESULT  RaftKind = 3
+	RaftKind_PROPOSE        RaftKind = 4 // A robot having completed the secret proposes it to the group
+	RaftKind_COMMITTED      RaftKind = 5 // The proposed secret was committed by a majority
+)
+
+// Enum value maps for RaftKind.
+var (
+	RaftKind_name = map[int32]string{
+		0: "REQUEST_VOTE",
+		1: "VOTE",
+		2: "APPEND_ENTRIES",
+		3: "APPEND_RESULT",
+		4: "PROPOSE",
+		5: "COMMITTED",
+	}
+	RaftKind_value = map[string]int32{
+		"REQUEST_VOTE":   0,
+		"VOTE":           1,
+		"APPEND_ENTRIES": 2,
+		"APPEND_RESULT":  3,
+		"PROPOSE":        4,
+		"COMMITTED":      5,
+	}
+)
+
+func (x RaftKind) Enum() *RaftKind {
+	p := new(RaftKind)
+	*p = x
+	return p
+}
+
+func (x RaftKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RaftKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_robot_proto_enumTypes[3].Descriptor()
+}
+
+func (RaftKind) Type() protoreflect.EnumType {
+	return &file_proto_robot_proto_enumTypes[3]
+}
+
+func (x RaftKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RaftKind.Descriptor instead.
+func (RaftKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{3}
+}
+
 type SecretPart struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Index         int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
 	Word          string                 `protobuf:"bytes,2,opt,name=word,proto3" json:"word,omitempty"`
+	OriginId      int32                  `protobuf:"varint,3,opt,name=origin_id,json=originId,proto3" json:"origin_id,omitempty"` // Robot that held the part when the secret was split
+	Sequence      uint64                 `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`                 // Position of the part among those of its origin, starting at 1
+	Data          []byte                 `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`                          // Share, fragment or raw token of the secret, the word is empty then
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -72,29 +302,52 @@ func (x *SecretPart) GetWord() string {
 	return ""
 }
 
-// A robot send his own indexes
-type GossipSummary struct {
+func (x *SecretPart) GetOriginId() int32 {
+	if x != nil {
+		return x.OriginId
+	}
+	return 0
+}
+
+func (x *SecretPart) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *SecretPart) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Parts of an origin robot integrated by a robot: every sequence up to counter,
+// plus the sequences received out of order (dots)
+type VersionEntry struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Indexes       []int64                `protobuf:"varint,1,rep,packed,name=indexes,proto3" json:"indexes,omitempty"`
-	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	OriginId      int32                  `protobuf:"varint,1,opt,name=origin_id,json=originId,proto3" json:"origin_id,omitempty"`
+	Counter       uint64                 `protobuf:"varint,2,opt,name=counter,proto3" json:"counter,omitempty"`
+	Dots          []uint64               `protobuf:"varint,3,rep,packed,name=dots,proto3" json:"dots,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GossipSummary) Reset() {
-	*x = GossipSummary{}
+func (x *VersionEntry) Reset() {
+	*x = VersionEntry{}
 	mi := &file_proto_robot_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GossipSummary) String() string {
+func (x *VersionEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GossipSummary) ProtoMessage() {}
+func (*VersionEntry) ProtoMessage() {}
 
-func (x *GossipSummary) ProtoReflect() protoreflect.Message {
+func (x *VersionEntry) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_robot_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -106,47 +359,56 @@ func (x *GossipSummary) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GossipSummary.ProtoReflect.Descriptor instead.
-func (*GossipSummary) Descriptor() ([]byte, []int) {
+// Deprecated: Use VersionEntry.ProtoReflect.Descriptor instead.
+func (*VersionEntry) Descriptor() ([]byte, []int) {
 	return file_proto_robot_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *GossipSummary) GetIndexes() []int64 {
+func (x *VersionEntry) GetOriginId() int32 {
 	if x != nil {
-		return x.Indexes
+		return x.OriginId
 	}
-	return nil
+	return 0
 }
 
-func (x *GossipSummary) GetSenderId() int32 {
+func (x *VersionEntry) GetCounter() uint64 {
 	if x != nil {
-		return x.SenderId
+		return x.Counter
 	}
 	return 0
 }
 
-// A robot responds his own secretParts (index, word)
-type GossipUpdate struct {
+func (x *VersionEntry) GetDots() []uint64 {
+	if x != nil {
+		return x.Dots
+	}
+	return nil
+}
+
+// Probabilistic set of (index, hash(word)) pairs
+type BloomFilter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SecretParts   []*SecretPart          `protobuf:"bytes,1,rep,name=secret_parts,json=secretParts,proto3" json:"secret_parts,omitempty"`
+	Bits          []byte                 `protobuf:"bytes,1,opt,name=bits,proto3" json:"bits,omitempty"`
+	Hashes        uint32                 `protobuf:"varint,2,opt,name=hashes,proto3" json:"hashes,omitempty"` // Number of hash functions
+	Items         uint32                 `protobuf:"varint,3,opt,name=items,proto3" json:"items,omitempty"`   // Number of pairs added to the filter
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GossipUpdate) Reset() {
-	*x = GossipUpdate{}
+func (x *BloomFilter) Reset() {
+	*x = BloomFilter{}
 	mi := &file_proto_robot_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GossipUpdate) String() string {
+func (x *BloomFilter) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GossipUpdate) ProtoMessage() {}
+func (*BloomFilter) ProtoMessage() {}
 
-func (x *GossipUpdate) ProtoReflect() protoreflect.Message {
+func (x *BloomFilter) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_robot_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -158,38 +420,1153 @@ func (x *GossipUpdate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GossipUpdate.ProtoReflect.Descriptor instead.
-func (*GossipUpdate) Descriptor() ([]byte, []int) {
+// Deprecated: Use BloomFilter.ProtoReflect.Descriptor instead.
+func (*BloomFilter) Descriptor() ([]byte, []int) {
 	return file_proto_robot_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *GossipUpdate) GetSecretParts() []*SecretPart {
+func (x *BloomFilter) GetBits() []byte {
 	if x != nil {
-		return x.SecretParts
+		return x.Bits
+	}
+	return nil
+}
+
+func (x *BloomFilter) GetHashes() uint32 {
+	if x != nil {
+		return x.Hashes
+	}
+	return 0
+}
+
+func (x *BloomFilter) GetItems() uint32 {
+	if x != nil {
+		return x.Items
+	}
+	return 0
+}
+
+// Interval of consecutive indexes, both bounds included
+type IndexRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int64                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexRange) Reset() {
+	*x = IndexRange{}
+	mi := &file_proto_robot_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexRange) ProtoMessage() {}
+
+func (x *IndexRange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexRange.ProtoReflect.Descriptor instead.
+func (*IndexRange) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IndexRange) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *IndexRange) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// A robot send his own indexes
+type GossipSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Indexes       []int64                `protobuf:"varint,1,rep,packed,name=indexes,proto3" json:"indexes,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Mode          GossipMode             `protobuf:"varint,3,opt,name=mode,proto3,enum=robots.proto.GossipMode" json:"mode,omitempty"`
+	Version       SummaryVersion         `protobuf:"varint,4,opt,name=version,proto3,enum=robots.proto.SummaryVersion" json:"version,omitempty"` // Tells the receiver which field holds the indexes
+	Ranges        []*IndexRange          `protobuf:"bytes,5,rep,name=ranges,proto3" json:"ranges,omitempty"`
+	Bitmap        []byte                 `protobuf:"bytes,6,opt,name=bitmap,proto3" json:"bitmap,omitempty"`
+	Bloom         *BloomFilter           `protobuf:"bytes,7,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	Digest        []byte                 `protobuf:"bytes,8,opt,name=digest,proto3" json:"digest,omitempty"`                                      // Hash of every held (index, word) pair, identical states are not reconciled
+	Vector        []*VersionEntry        `protobuf:"bytes,9,rep,name=vector,proto3" json:"vector,omitempty"`                                      // Version vector of the sender, dominated receivers have nothing to send
+	Lamport       uint64                 `protobuf:"varint,10,opt,name=lamport,proto3" json:"lamport,omitempty"`                                  // Lamport timestamp of the sender when the message was sent
+	SnapshotEpoch uint64                 `protobuf:"varint,11,opt,name=snapshot_epoch,json=snapshotEpoch,proto3" json:"snapshot_epoch,omitempty"` // Last global snapshot recorded by the sender, acts as a marker
+	Marker        bool                   `protobuf:"varint,12,opt,name=marker,proto3" json:"marker,omitempty"`                                    // Chandy-Lamport marker only, there is no summary to process
+	SecretId      string                 `protobuf:"bytes,13,opt,name=secret_id,json=secretId,proto3" json:"secret_id,omitempty"`                 // Secret summarized, empty for the first secret of the run
+	Others        []*GossipSummary       `protobuf:"bytes,14,rep,name=others,proto3" json:"others,omitempty"`                                     // Summaries of the other secrets of the sender, sent in the same message
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GossipSummary) Reset() {
+	*x = GossipSummary{}
+	mi := &file_proto_robot_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GossipSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GossipSummary) ProtoMessage() {}
+
+func (x *GossipSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GossipSummary.ProtoReflect.Descriptor instead.
+func (*GossipSummary) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GossipSummary) GetIndexes() []int64 {
+	if x != nil {
+		return x.Indexes
 	}
 	return nil
 }
 
+func (x *GossipSummary) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *GossipSummary) GetMode() GossipMode {
+	if x != nil {
+		return x.Mode
+	}
+	return GossipMode_PULL
+}
+
+func (x *GossipSummary) GetVersion() SummaryVersion {
+	if x != nil {
+		return x.Version
+	}
+	return SummaryVersion_INDEX_LIST
+}
+
+func (x *GossipSummary) GetRanges() []*IndexRange {
+	if x != nil {
+		return x.Ranges
+	}
+	return nil
+}
+
+func (x *GossipSummary) GetBitmap() []byte {
+	if x != nil {
+		return x.Bitmap
+	}
+	return nil
+}
+
+func (x *GossipSummary) GetBloom() *BloomFilter {
+	if x != nil {
+		return x.Bloom
+	}
+	return nil
+}
+
+func (x *GossipSummary) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *GossipSummary) GetVector() []*VersionEntry {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+func (x *GossipSummary) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+func (x *GossipSummary) GetSnapshotEpoch() uint64 {
+	if x != nil {
+		return x.SnapshotEpoch
+	}
+	return 0
+}
+
+func (x *GossipSummary) GetMarker() bool {
+	if x != nil {
+		return x.Marker
+	}
+	return false
+}
+
+func (x *GossipSummary) GetSecretId() string {
+	if x != nil {
+		return x.SecretId
+	}
+	return ""
+}
+
+func (x *GossipSummary) GetOthers() []*GossipSummary {
+	if x != nil {
+		return x.Others
+	}
+	return nil
+}
+
+// A robot responds his own secretParts (index, word)
+type GossipUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SecretParts   []*SecretPart          `protobuf:"bytes,1,rep,name=secret_parts,json=secretParts,proto3" json:"secret_parts,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Lamport       uint64                 `protobuf:"varint,3,opt,name=lamport,proto3" json:"lamport,omitempty"`                                  // Lamport timestamp of the sender when the message was sent
+	SnapshotEpoch uint64                 `protobuf:"varint,4,opt,name=snapshot_epoch,json=snapshotEpoch,proto3" json:"snapshot_epoch,omitempty"` // Last global snapshot recorded by the sender, acts as a marker
+	Marker        bool                   `protobuf:"varint,5,opt,name=marker,proto3" json:"marker,omitempty"`                                    // Chandy-Lamport marker only, there are no parts to merge
+	SecretId      string                 `protobuf:"bytes,6,opt,name=secret_id,json=secretId,proto3" json:"secret_id,omitempty"`                 // Secret of the parts, empty for the first secret of the run
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GossipUpdate) Reset() {
+	*x = GossipUpdate{}
+	mi := &file_proto_robot_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GossipUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GossipUpdate) ProtoMessage() {}
+
+func (x *GossipUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GossipUpdate.ProtoReflect.Descriptor instead.
+func (*GossipUpdate) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GossipUpdate) GetSecretParts() []*SecretPart {
+	if x != nil {
+		return x.SecretParts
+	}
+	return nil
+}
+
+func (x *GossipUpdate) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *GossipUpdate) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+func (x *GossipUpdate) GetSnapshotEpoch() uint64 {
+	if x != nil {
+		return x.SnapshotEpoch
+	}
+	return 0
+}
+
+func (x *GossipUpdate) GetMarker() bool {
+	if x != nil {
+		return x.Marker
+	}
+	return false
+}
+
+func (x *GossipUpdate) GetSecretId() string {
+	if x != nil {
+		return x.SecretId
+	}
+	return ""
+}
+
+// Hash of a node of a Merkle tree over the index space
+// The root is at position 1 and the children of position p are 2p and 2p+1
+type MerkleNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      uint32                 `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	Hash          []byte                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerkleNode) Reset() {
+	*x = MerkleNode{}
+	mi := &file_proto_robot_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleNode) ProtoMessage() {}
+
+func (x *MerkleNode) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleNode.ProtoReflect.Descriptor instead.
+func (*MerkleNode) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MerkleNode) GetPosition() uint32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *MerkleNode) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+// Step of a Merkle reconciliation between two robots
+// Robots alternate, only descending into the subtrees whose hashes differ
+type MerkleExchange struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SenderId        int32                  `protobuf:"varint,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ExchangeId      uint64                 `protobuf:"varint,2,opt,name=exchange_id,json=exchangeId,proto3" json:"exchange_id,omitempty"`
+	Leaves          uint32                 `protobuf:"varint,3,opt,name=leaves,proto3" json:"leaves,omitempty"`                                                 // Both trees must have the same number of leaves
+	Nodes           []*MerkleNode          `protobuf:"bytes,4,rep,name=nodes,proto3" json:"nodes,omitempty"`                                                    // Nodes of the sender to compare with
+	RequestedLeaves []uint32               `protobuf:"varint,5,rep,packed,name=requested_leaves,json=requestedLeaves,proto3" json:"requested_leaves,omitempty"` // Differing leaves whose parts the sender wants
+	Rounds          uint32                 `protobuf:"varint,6,opt,name=rounds,proto3" json:"rounds,omitempty"`                                                 // Messages exchanged so far
+	Bytes           uint64                 `protobuf:"varint,7,opt,name=bytes,proto3" json:"bytes,omitempty"`                                                   // Bytes exchanged so far
+	Parts           uint32                 `protobuf:"varint,8,opt,name=parts,proto3" json:"parts,omitempty"`                                                   // Secret parts transferred so far
+	Lamport         uint64                 `protobuf:"varint,9,opt,name=lamport,proto3" json:"lamport,omitempty"`                                               // Lamport timestamp of the sender when the message was sent
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *MerkleExchange) Reset() {
+	*x = MerkleExchange{}
+	mi := &file_proto_robot_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleExchange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleExchange) ProtoMessage() {}
+
+func (x *MerkleExchange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleExchange.ProtoReflect.Descriptor instead.
+func (*MerkleExchange) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MerkleExchange) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *MerkleExchange) GetExchangeId() uint64 {
+	if x != nil {
+		return x.ExchangeId
+	}
+	return 0
+}
+
+func (x *MerkleExchange) GetLeaves() uint32 {
+	if x != nil {
+		return x.Leaves
+	}
+	return 0
+}
+
+func (x *MerkleExchange) GetNodes() []*MerkleNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *MerkleExchange) GetRequestedLeaves() []uint32 {
+	if x != nil {
+		return x.RequestedLeaves
+	}
+	return nil
+}
+
+func (x *MerkleExchange) GetRounds() uint32 {
+	if x != nil {
+		return x.Rounds
+	}
+	return 0
+}
+
+func (x *MerkleExchange) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *MerkleExchange) GetParts() uint32 {
+	if x != nil {
+		return x.Parts
+	}
+	return 0
+}
+
+func (x *MerkleExchange) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+// A robot spreads its hot rumors (recently learned secretParts)
+type RumorPush struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SecretParts   []*SecretPart          `protobuf:"bytes,1,rep,name=secret_parts,json=secretParts,proto3" json:"secret_parts,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Lamport       uint64                 `protobuf:"varint,3,opt,name=lamport,proto3" json:"lamport,omitempty"`                                  // Lamport timestamp of the sender when the message was sent
+	SnapshotEpoch uint64                 `protobuf:"varint,4,opt,name=snapshot_epoch,json=snapshotEpoch,proto3" json:"snapshot_epoch,omitempty"` // Last global snapshot recorded by the sender, acts as a marker
+	Marker        bool                   `protobuf:"varint,5,opt,name=marker,proto3" json:"marker,omitempty"`                                    // Chandy-Lamport marker only, there is no rumor to merge
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RumorPush) Reset() {
+	*x = RumorPush{}
+	mi := &file_proto_robot_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RumorPush) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RumorPush) ProtoMessage() {}
+
+func (x *RumorPush) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RumorPush.ProtoReflect.Descriptor instead.
+func (*RumorPush) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RumorPush) GetSecretParts() []*SecretPart {
+	if x != nil {
+		return x.SecretParts
+	}
+	return nil
+}
+
+func (x *RumorPush) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *RumorPush) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+func (x *RumorPush) GetSnapshotEpoch() uint64 {
+	if x != nil {
+		return x.SnapshotEpoch
+	}
+	return 0
+}
+
+func (x *RumorPush) GetMarker() bool {
+	if x != nil {
+		return x.Marker
+	}
+	return false
+}
+
+// A robot tells which pushed secretParts it already knew
+type RumorFeedback struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KnownIndexes  []int64                `protobuf:"varint,1,rep,packed,name=known_indexes,json=knownIndexes,proto3" json:"known_indexes,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Lamport       uint64                 `protobuf:"varint,3,opt,name=lamport,proto3" json:"lamport,omitempty"` // Lamport timestamp of the sender when the message was sent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RumorFeedback) Reset() {
+	*x = RumorFeedback{}
+	mi := &file_proto_robot_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RumorFeedback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RumorFeedback) ProtoMessage() {}
+
+func (x *RumorFeedback) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RumorFeedback.ProtoReflect.Descriptor instead.
+func (*RumorFeedback) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RumorFeedback) GetKnownIndexes() []int64 {
+	if x != nil {
+		return x.KnownIndexes
+	}
+	return nil
+}
+
+func (x *RumorFeedback) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *RumorFeedback) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+// Token of the Dijkstra-Safra termination detection, passed along the ring of robots
+// Each robot adds its balance of basic messages (sent - received) to count
+// and blackens the token if it received a basic message since its last visit
+type TerminationToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Wave          uint64                 `protobuf:"varint,1,opt,name=wave,proto3" json:"wave,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Black         bool                   `protobuf:"varint,3,opt,name=black,proto3" json:"black,omitempty"`
+	SenderId      int32                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Lamport       uint64                 `protobuf:"varint,5,opt,name=lamport,proto3" json:"lamport,omitempty"` // Lamport timestamp of the sender when the message was sent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TerminationToken) Reset() {
+	*x = TerminationToken{}
+	mi := &file_proto_robot_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TerminationToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminationToken) ProtoMessage() {}
+
+func (x *TerminationToken) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminationToken.ProtoReflect.Descriptor instead.
+func (*TerminationToken) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TerminationToken) GetWave() uint64 {
+	if x != nil {
+		return x.Wave
+	}
+	return 0
+}
+
+func (x *TerminationToken) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *TerminationToken) GetBlack() bool {
+	if x != nil {
+		return x.Black
+	}
+	return false
+}
+
+func (x *TerminationToken) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *TerminationToken) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+type ElectionMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          ElectionKind           `protobuf:"varint,1,opt,name=kind,proto3,enum=robots.proto.ElectionKind" json:"kind,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Term          uint64                 `protobuf:"varint,3,opt,name=term,proto3" json:"term,omitempty"`       // Election round of the candidate
+	Lamport       uint64                 `protobuf:"varint,4,opt,name=lamport,proto3" json:"lamport,omitempty"` // Lamport timestamp of the sender when the message was sent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ElectionMessage) Reset() {
+	*x = ElectionMessage{}
+	mi := &file_proto_robot_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ElectionMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ElectionMessage) ProtoMessage() {}
+
+func (x *ElectionMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ElectionMessage.ProtoReflect.Descriptor instead.
+func (*ElectionMessage) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ElectionMessage) GetKind() ElectionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ElectionKind_ELECTION
+}
+
+func (x *ElectionMessage) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *ElectionMessage) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *ElectionMessage) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
+// Entry of the replicated log, a leader appends an empty entry when elected
+type RaftEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	ProposerId    int32                  `protobuf:"varint,2,opt,name=proposer_id,json=proposerId,proto3" json:"proposer_id,omitempty"`
+	Secret        []byte                 `protobuf:"bytes,3,opt,name=secret,proto3" json:"secret,omitempty"` // Raw bytes, the secret may not be valid UTF-8
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RaftEntry) Reset() {
+	*x = RaftEntry{}
+	mi := &file_proto_robot_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RaftEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RaftEntry) ProtoMessage() {}
+
+func (x *RaftEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RaftEntry.ProtoReflect.Descriptor instead.
+func (*RaftEntry) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RaftEntry) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *RaftEntry) GetProposerId() int32 {
+	if x != nil {
+		return x.ProposerId
+	}
+	return 0
+}
+
+func (x *RaftEntry) GetSecret() []byte {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+type RaftMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          RaftKind               `protobuf:"varint,1,opt,name=kind,proto3,enum=robots.proto.RaftKind" json:"kind,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Term          uint64                 `protobuf:"varint,3,opt,name=term,proto3" json:"term,omitempty"`
+	LastLogIndex  uint64                 `protobuf:"varint,4,opt,name=last_log_index,json=lastLogIndex,proto3" json:"last_log_index,omitempty"` // Request vote: how up to date the candidate's log is
+	LastLogTerm   uint64                 `protobuf:"varint,5,opt,name=last_log_term,json=lastLogTerm,proto3" json:"last_log_term,omitempty"`
+	Granted       bool                   `protobuf:"varint,6,opt,name=granted,proto3" json:"granted,omitempty"`                                 // Vote granted, or entries appended
+	PrevLogIndex  uint64                 `protobuf:"varint,7,opt,name=prev_log_index,json=prevLogIndex,proto3" json:"prev_log_index,omitempty"` // Append entries: entry preceding the new ones
+	PrevLogTerm   uint64                 `protobuf:"varint,8,opt,name=prev_log_term,json=prevLogTerm,proto3" json:"prev_log_term,omitempty"`
+	Entries       []*RaftEntry           `protobuf:"bytes,9,rep,name=entries,proto3" json:"entries,omitempty"` // Also the proposed or committed entry
+	LeaderCommit  uint64                 `protobuf:"varint,10,opt,name=leader_commit,json=leaderCommit,proto3" json:"leader_commit,omitempty"`
+	MatchIndex    uint64                 `protobuf:"varint,11,opt,name=match_index,json=matchIndex,proto3" json:"match_index,omitempty"` // Append result: last entry matching the leader's log
+	Lamport       uint64                 `protobuf:"varint,12,opt,name=lamport,proto3" json:"lamport,omitempty"`                         // Lamport timestamp of the sender when the message was sent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RaftMessage) Reset() {
+	*x = RaftMessage{}
+	mi := &file_proto_robot_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RaftMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RaftMessage) ProtoMessage() {}
+
+func (x *RaftMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_robot_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RaftMessage.ProtoReflect.Descriptor instead.
+func (*RaftMessage) Descriptor() ([]byte, []int) {
+	return file_proto_robot_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RaftMessage) GetKind() RaftKind {
+	if x != nil {
+		return x.Kind
+	}
+	return RaftKind_REQUEST_VOTE
+}
+
+func (x *RaftMessage) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetLastLogIndex() uint64 {
+	if x != nil {
+		return x.LastLogIndex
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetLastLogTerm() uint64 {
+	if x != nil {
+		return x.LastLogTerm
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetGranted() bool {
+	if x != nil {
+		return x.Granted
+	}
+	return false
+}
+
+func (x *RaftMessage) GetPrevLogIndex() uint64 {
+	if x != nil {
+		return x.PrevLogIndex
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetPrevLogTerm() uint64 {
+	if x != nil {
+		return x.PrevLogTerm
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetEntries() []*RaftEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *RaftMessage) GetLeaderCommit() uint64 {
+	if x != nil {
+		return x.LeaderCommit
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetMatchIndex() uint64 {
+	if x != nil {
+		return x.MatchIndex
+	}
+	return 0
+}
+
+func (x *RaftMessage) GetLamport() uint64 {
+	if x != nil {
+		return x.Lamport
+	}
+	return 0
+}
+
 var File_proto_robot_proto protoreflect.FileDescriptor
 
 var file_proto_robot_proto_rawDesc = []byte{
 	0x0a, 0x11, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x2e, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x22, 0x36, 0x0a, 0x0a, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74, 0x12,
-	0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
-	0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x46, 0x0a, 0x0d, 0x47, 0x6f, 0x73,
-	0x73, 0x69, 0x70, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e,
-	0x64, 0x65, 0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x07, 0x69, 0x6e, 0x64,
-	0x65, 0x78, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x69,
-	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x49,
-	0x64, 0x22, 0x4b, 0x0a, 0x0c, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x55, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x12, 0x3b, 0x0a, 0x0c, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x5f, 0x70, 0x61, 0x72, 0x74,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x50, 0x61, 0x72,
-	0x74, 0x52, 0x0b, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74, 0x73, 0x42, 0x17,
-	0x5a, 0x15, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70,
-	0x62, 0x2d, 0x67, 0x6f, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x22, 0x83, 0x01, 0x0a, 0x0a, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6f,
+	0x72, 0x69, 0x67, 0x69, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x59, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x72, 0x69, 0x67, 0x69,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x12,
+	0x0a, 0x04, 0x64, 0x6f, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x04, 0x52, 0x04, 0x64, 0x6f,
+	0x74, 0x73, 0x22, 0x4f, 0x0a, 0x0b, 0x42, 0x6c, 0x6f, 0x6f, 0x6d, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x62, 0x69, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x74,
+	0x65, 0x6d, 0x73, 0x22, 0x34, 0x0a, 0x0a, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x22, 0x9e, 0x04, 0x0a, 0x0d, 0x47, 0x6f,
+	0x73, 0x73, 0x69, 0x70, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x07, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x2c, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x18, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65,
+	0x12, 0x36, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x1c, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x06, 0x72, 0x61, 0x6e, 0x67,
+	0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x52, 0x06, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x69,
+	0x74, 0x6d, 0x61, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x62, 0x69, 0x74, 0x6d,
+	0x61, 0x70, 0x12, 0x2f, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x6f, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x42, 0x6c, 0x6f, 0x6f, 0x6d, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x05, 0x62, 0x6c,
+	0x6f, 0x6f, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x06, 0x76,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x72, 0x6f,
+	0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12,
+	0x18, 0x0a, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x45, 0x70, 0x6f, 0x63, 0x68,
+	0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x63, 0x72,
+	0x65, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x63,
+	0x72, 0x65, 0x74, 0x49, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x73, 0x18,
+	0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x53, 0x75, 0x6d, 0x6d, 0x61,
+	0x72, 0x79, 0x52, 0x06, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x73, 0x22, 0xde, 0x01, 0x0a, 0x0c, 0x47,
+	0x6f, 0x73, 0x73, 0x69, 0x70, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x3b, 0x0a, 0x0c, 0x73,
+	0x65, 0x63, 0x72, 0x65, 0x74, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74, 0x52, 0x0b, 0x73, 0x65, 0x63,
+	0x72, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x64,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x65, 0x6e,
+	0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x25, 0x0a, 0x0e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x65, 0x70, 0x6f, 0x63,
+	0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x49, 0x64, 0x22, 0x3c, 0x0a, 0x0a, 0x4d,
+	0x65, 0x72, 0x6b, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x9f, 0x02, 0x0a, 0x0e, 0x4d, 0x65,
+	0x72, 0x6b, 0x6c, 0x65, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09,
+	0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x78, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a,
+	0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65,
+	0x61, 0x76, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x76,
+	0x65, 0x73, 0x12, 0x2e, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64,
+	0x65, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f,
+	0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x0f, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70,
+	0x61, 0x72, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x61, 0x72, 0x74,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x22, 0xbe, 0x01, 0x0a, 0x09,
+	0x52, 0x75, 0x6d, 0x6f, 0x72, 0x50, 0x75, 0x73, 0x68, 0x12, 0x3b, 0x0a, 0x0c, 0x73, 0x65, 0x63,
+	0x72, 0x65, 0x74, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53,
+	0x65, 0x63, 0x72, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74, 0x52, 0x0b, 0x73, 0x65, 0x63, 0x72, 0x65,
+	0x74, 0x50, 0x61, 0x72, 0x74, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x65, 0x6e, 0x64, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x25, 0x0a,
+	0x0e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x45,
+	0x70, 0x6f, 0x63, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x22, 0x6b, 0x0a, 0x0d,
+	0x52, 0x75, 0x6d, 0x6f, 0x72, 0x46, 0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x23, 0x0a,
+	0x0d, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x03, 0x52, 0x0c, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x22, 0x89, 0x01, 0x0a, 0x10, 0x54, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x77, 0x61, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x77, 0x61,
+	0x76, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6c, 0x61, 0x63,
+	0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6c,
+	0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6c, 0x61,
+	0x6d, 0x70, 0x6f, 0x72, 0x74, 0x22, 0x8c, 0x01, 0x0a, 0x0f, 0x45, 0x6c, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4b,
+	0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e,
+	0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x65,
+	0x6e, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x61,
+	0x6d, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6c, 0x61, 0x6d,
+	0x70, 0x6f, 0x72, 0x74, 0x22, 0x58, 0x0a, 0x09, 0x52, 0x61, 0x66, 0x74, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x74, 0x65, 0x72, 0x6d, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x22, 0xab,
+	0x03, 0x0a, 0x0b, 0x52, 0x61, 0x66, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2a,
+	0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x72,
+	0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x61, 0x66, 0x74,
+	0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65,
+	0x6e, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73,
+	0x65, 0x6e, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12, 0x24, 0x0a, 0x0e, 0x6c,
+	0x61, 0x73, 0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x4c, 0x6f, 0x67, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x12, 0x22, 0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x74, 0x65,
+	0x72, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x4c, 0x6f,
+	0x67, 0x54, 0x65, 0x72, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x12,
+	0x24, 0x0a, 0x0e, 0x70, 0x72, 0x65, 0x76, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x4c, 0x6f, 0x67,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x22, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x5f, 0x6c, 0x6f,
+	0x67, 0x5f, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x70, 0x72,
+	0x65, 0x76, 0x4c, 0x6f, 0x67, 0x54, 0x65, 0x72, 0x6d, 0x12, 0x31, 0x0a, 0x07, 0x65, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x6f, 0x62,
+	0x6f, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x61, 0x66, 0x74, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d,
+	0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x6c, 0x61, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x2a, 0x2f, 0x0a, 0x0a,
+	0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x55,
+	0x4c, 0x4c, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x55, 0x53, 0x48, 0x10, 0x01, 0x12, 0x0d,
+	0x0a, 0x09, 0x50, 0x55, 0x53, 0x48, 0x5f, 0x50, 0x55, 0x4c, 0x4c, 0x10, 0x02, 0x2a, 0x50, 0x0a,
+	0x0e, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x0e, 0x0a, 0x0a, 0x49, 0x4e, 0x44, 0x45, 0x58, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x00, 0x12,
+	0x0a, 0x0a, 0x06, 0x52, 0x41, 0x4e, 0x47, 0x45, 0x53, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x42,
+	0x49, 0x54, 0x4d, 0x41, 0x50, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f, 0x4f, 0x4d,
+	0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4f, 0x4d, 0x50, 0x41, 0x43, 0x54, 0x10, 0x04, 0x2a,
+	0x80, 0x01, 0x0a, 0x0c, 0x45, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x69, 0x6e, 0x64,
+	0x12, 0x0c, 0x0a, 0x08, 0x45, 0x4c, 0x45, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x00, 0x12, 0x0a,
+	0x0a, 0x06, 0x41, 0x4e, 0x53, 0x57, 0x45, 0x52, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x43, 0x4f,
+	0x4f, 0x52, 0x44, 0x49, 0x4e, 0x41, 0x54, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x4c,
+	0x45, 0x41, 0x53, 0x45, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x10, 0x03, 0x12, 0x0f,
+	0x0a, 0x0b, 0x4c, 0x45, 0x41, 0x53, 0x45, 0x5f, 0x47, 0x52, 0x41, 0x4e, 0x54, 0x10, 0x04, 0x12,
+	0x10, 0x0a, 0x0c, 0x4c, 0x45, 0x41, 0x53, 0x45, 0x5f, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x10,
+	0x05, 0x12, 0x0f, 0x0a, 0x0b, 0x4c, 0x45, 0x41, 0x53, 0x45, 0x5f, 0x52, 0x45, 0x4e, 0x45, 0x57,
+	0x10, 0x06, 0x2a, 0x69, 0x0a, 0x08, 0x52, 0x61, 0x66, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x10,
+	0x0a, 0x0c, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x5f, 0x56, 0x4f, 0x54, 0x45, 0x10, 0x00,
+	0x12, 0x08, 0x0a, 0x04, 0x56, 0x4f, 0x54, 0x45, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x50,
+	0x50, 0x45, 0x4e, 0x44, 0x5f, 0x45, 0x4e, 0x54, 0x52, 0x49, 0x45, 0x53, 0x10, 0x02, 0x12, 0x11,
+	0x0a, 0x0d, 0x41, 0x50, 0x50, 0x45, 0x4e, 0x44, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x10,
+	0x03, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x52, 0x4f, 0x50, 0x4f, 0x53, 0x45, 0x10, 0x04, 0x12, 0x0d,
+	0x0a, 0x09, 0x43, 0x4f, 0x4d, 0x4d, 0x49, 0x54, 0x54, 0x45, 0x44, 0x10, 0x05, 0x42, 0x17, 0x5a,
+	0x15, 0x72, 0x6f, 0x62, 0x6f, 0x74, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x62,
+	0x2d, 0x67, 0x6f, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -204,19 +1581,46 @@ func file_proto_robot_proto_rawDescGZIP() []byte {
 	return file_proto_robot_proto_rawDescData
 }
 
-var file_proto_robot_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_robot_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_robot_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_proto_robot_proto_goTypes = []any{
-	(*SecretPart)(nil),    // 0: robots.proto.SecretPart
-	(*GossipSummary)(nil), // 1: robots.proto.GossipSummary
-	(*GossipUpdate)(nil),  // 2: robots.proto.GossipUpdate
+	(GossipMode)(0),          // 0: robots.proto.GossipMode
+	(SummaryVersion)(0),      // 1: robots.proto.SummaryVersion
+	(ElectionKind)(0),        // 2: robots.proto.ElectionKind
+	(RaftKind)(0),            // 3: robots.proto.RaftKind
+	(*SecretPart)(nil),       // 4: robots.proto.SecretPart
+	(*VersionEntry)(nil),     // 5: robots.proto.VersionEntry
+	(*BloomFilter)(nil),      // 6: robots.proto.BloomFilter
+	(*IndexRange)(nil),       // 7: robots.proto.IndexRange
+	(*GossipSummary)(nil),    // 8: robots.proto.GossipSummary
+	(*GossipUpdate)(nil),     // 9: robots.proto.GossipUpdate
+	(*MerkleNode)(nil),       // 10: robots.proto.MerkleNode
+	(*MerkleExchange)(nil),   // 11: robots.proto.MerkleExchange
+	(*RumorPush)(nil),        // 12: robots.proto.RumorPush
+	(*RumorFeedback)(nil),    // 13: robots.proto.RumorFeedback
+	(*TerminationToken)(nil), // 14: robots.proto.TerminationToken
+	(*ElectionMessage)(nil),  // 15: robots.proto.ElectionMessage
+	(*RaftEntry)(nil),        // 16: robots.proto.RaftEntry
+	(*RaftMessage)(nil),      // 17: robots.proto.RaftMessage
 }
 var file_proto_robot_proto_depIdxs = []int32{
-	0, // 0: robots.proto.GossipUpdate.secret_parts:type_name -> robots.proto.SecretPart
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: robots.proto.GossipSummary.mode:type_name -> robots.proto.GossipMode
+	1,  // 1: robots.proto.GossipSummary.version:type_name -> robots.proto.SummaryVersion
+	7,  // 2: robots.proto.GossipSummary.ranges:type_name -> robots.proto.IndexRange
+	6,  // 3: robots.proto.GossipSummary.bloom:type_name -> robots.proto.BloomFilter
+	5,  // 4: robots.proto.GossipSummary.vector:type_name -> robots.proto.VersionEntry
+	8,  // 5: robots.proto.GossipSummary.others:type_name -> robots.proto.GossipSummary
+	4,  // 6: robots.proto.GossipUpdate.secret_parts:type_name -> robots.proto.SecretPart
+	10, // 7: robots.proto.MerkleExchange.nodes:type_name -> robots.proto.MerkleNode
+	4,  // 8: robots.proto.RumorPush.secret_parts:type_name -> robots.proto.SecretPart
+	2,  // 9: robots.proto.ElectionMessage.kind:type_name -> robots.proto.ElectionKind
+	3,  // 10: robots.proto.RaftMessage.kind:type_name -> robots.proto.RaftKind
+	16, // 11: robots.proto.RaftMessage.entries:type_name -> robots.proto.RaftEntry
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_proto_robot_proto_init() }
@@ -229,13 +1633,14 @@ func file_proto_robot_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_robot_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      4,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_proto_robot_proto_goTypes,
 		DependencyIndexes: file_proto_robot_proto_depIdxs,
+		EnumInfos:         file_proto_robot_proto_enumTypes,
 		MessageInfos:      file_proto_robot_proto_msgTypes,
 	}.Build()
 	File_proto_robot_proto = out.File
@@ -3,15 +3,18 @@ package tests
 import (
 	"context"
 	"errors"
+	"io"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"robots/internal/conf"
 	"robots/pkg/events"
+	"robots/pkg/output"
 	"robots/pkg/robot"
 	"robots/pkg/workers"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -48,10 +51,12 @@ func TestEndToEnd_WritesOutputFile(t *testing.T) {
 	robots := sm.CreateRobots(strings.Fields(cfg.Secret))
 	eventsCh := make(chan events.Event, 100)
 
+	go handleEvents(ctx, eventsCh, events.NewWinnerElectedHandler(cfg, slog.Default(), robots, &sync.Once{}, sink(t, cfg), nil))
+
 	// Start workers
 	for _, r := range robots {
 		go workers.NewMergeSecretWorker(slog.Default(), r, eventsCh).Run(ctx)
-		go workers.NewProcessSummaryWorker(slog.Default(), r, robots, eventsCh).Run(ctx)
+		go workers.NewProcessSummaryWorker(cfg, slog.Default(), r, robots, eventsCh).Run(ctx)
 		go workers.NewStartGossipWorker(cfg, slog.Default(), r, robots, eventsCh).Run(ctx)
 		go workers.NewConvergenceDetectorWorker(cfg, slog.Default(), r, eventsCh).Run(ctx)
 	}
@@ -98,7 +103,7 @@ func TestDoesNotWriteFileBeforeQuiescence(t *testing.T) {
 	// Start workers
 	for _, r := range robots {
 		go workers.NewMergeSecretWorker(logger, r, eventsCh).Run(ctx)
-		go workers.NewProcessSummaryWorker(logger, r, robots, eventsCh).Run(ctx)
+		go workers.NewProcessSummaryWorker(cfg, logger, r, robots, eventsCh).Run(ctx)
 		go workers.NewStartGossipWorker(cfg, logger, r, robots, eventsCh).Run(ctx)
 		go workers.NewConvergenceDetectorWorker(cfg, logger, r, eventsCh).Run(ctx)
 	}
@@ -127,16 +132,10 @@ func TestWinnerChannelBlocksFileWrite(t *testing.T) {
 
 	word := "Hidden."
 
-	r1 := &robot.Robot{
-		ID:            1,
-		SecretParts:   []robot.SecretPart{{Word: word}},
-		LastUpdatedAt: time.Now().Add(-2 * time.Second),
-	}
-	r2 := &robot.Robot{
-		ID:            2,
-		SecretParts:   []robot.SecretPart{{Word: word}},
-		LastUpdatedAt: time.Now().Add(-2 * time.Second),
-	}
+	r1 := robot.NewRobot(0, robot.SecretIntegrity{}, robot.SecretPart{Word: word})
+	r1.LastUpdatedAt = time.Now().Add(-2 * time.Second)
+	r2 := robot.NewRobot(1, robot.SecretIntegrity{}, robot.SecretPart{Word: word})
+	r2.LastUpdatedAt = time.Now().Add(-2 * time.Second)
 
 	w1 := workers.NewConvergenceDetectorWorker(cfg, logger, r1, eventsCh)
 	w2 := workers.NewConvergenceDetectorWorker(cfg, logger, r2, eventsCh)
@@ -144,6 +143,7 @@ func TestWinnerChannelBlocksFileWrite(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
+	go handleEvents(ctx, eventsCh, events.NewWinnerElectedHandler(cfg, logger, []*robot.Robot{r1, r2}, &sync.Once{}, sink(t, cfg), nil))
 	go w1.Run(ctx)
 	go w2.Run(ctx)
 
@@ -153,3 +153,130 @@ func TestWinnerChannelBlocksFileWrite(t *testing.T) {
 	ass.NoError(err)
 	ass.Equal(word, string(content))
 }
+
+// TestSecrets_ConvergeThroughSharedChannels Two secrets gossiped at once: the summaries of the
+// second one only travel in the Others of the first one's, and the updates are routed by secret id
+func TestSecrets_ConvergeThroughSharedChannels(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "secret.txt")
+	cfg := conf.Config{
+		NbrOfRobots: 4,
+		OutputFile:  outputFile,
+		BufferSize:  100,
+		EndOfSecret: ".",
+		MaxAttempts: 5,
+		GossipTime:  20 * time.Millisecond,
+		QuietPeriod: 200 * time.Millisecond,
+		Timeout:     3 * time.Second,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	inputs := map[robot.SecretID]string{"": "hello world.", "bridge": "under the old bridge."}
+	sm := robot.SecretManager{Config: cfg}
+	robots := sm.CreateRobots(strings.Fields(inputs[""]))
+	secrets := robot.Secrets{"": robots, "bridge": sm.CreateSecret("bridge", strings.Fields(inputs["bridge"]), robots)}
+	eventsCh := make(chan events.Event, 100)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	bridgeConfig := cfg
+	bridgeConfig.OutputFile = output.SecretPath(outputFile, "bridge")
+	go handleEvents(ctx, eventsCh,
+		events.NewWinnerElectedHandler(cfg, logger, robots, &sync.Once{}, sink(t, cfg), nil),
+		events.NewWinnerElectedHandler(bridgeConfig, logger, secrets["bridge"], &sync.Once{}, sink(t, bridgeConfig), nil).WithSecret("bridge"),
+	)
+	// Only the robots of the first secret have workers, the second one shares them
+	for _, r := range robots {
+		go workers.NewMergeSecretWorker(logger, r, eventsCh).WithSecrets(secrets).Run(ctx)
+		go workers.NewProcessSummaryWorker(cfg, logger, r, robots, eventsCh).WithSecrets(secrets).Run(ctx)
+		go workers.NewStartGossipWorker(cfg, logger, r, robots, eventsCh).WithSecrets(secrets).Run(ctx)
+	}
+	for _, r := range secrets.All() {
+		go workers.NewConvergenceDetectorWorker(cfg, logger, r, eventsCh).Run(ctx)
+	}
+
+	for id, input := range inputs {
+		path := output.SecretPath(outputFile, string(id))
+		require.Eventually(t, func() bool {
+			content, err := os.ReadFile(path)
+			return err == nil && string(content) == input
+		}, cfg.Timeout, 50*time.Millisecond, "secret %q written to %s", id, path)
+		for _, r := range secrets[id] {
+			assert.Equal(t, strings.Fields(input), r.GetWords(true), "robot %d of secret %q only holds its parts", r.ID, id)
+		}
+	}
+}
+
+// TestWinnerElected_TheTermFencesTheWriter With an election, only the winner of the latest term writes the secret
+func TestWinnerElected_TheTermFencesTheWriter(t *testing.T) {
+	ass := assert.New(t)
+	cfg := conf.Config{NbrOfRobots: 3, OutputFile: filepath.Join(t.TempDir(), "secret.txt"), EndOfSecret: ".", Election: "lease"}
+	robots := []*robot.Robot{
+		robot.NewRobot(0, robot.SecretIntegrity{}, robot.SecretPart{Index: 0, Word: "first."}),
+		robot.NewRobot(1, robot.SecretIntegrity{}, robot.SecretPart{Index: 0, Word: "stale."}),
+		robot.NewRobot(2, robot.SecretIntegrity{}, robot.SecretPart{Index: 0, Word: "next."}),
+	}
+	domainEvent := make(chan events.Event, 10)
+	handler := events.NewWinnerElectedHandler(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), robots, &sync.Once{}, sink(t, cfg), domainEvent)
+	win := func(id int, term uint64) {
+		handler.Handle(events.Event{EventType: events.EventWinnerElected, Payload: events.WinnerElectedEvent{ID: id, Term: term}})
+	}
+	written := func(secret string) func() bool {
+		return func() bool {
+			content, err := os.ReadFile(cfg.OutputFile)
+			return err == nil && string(content) == secret
+		}
+	}
+
+	win(0, 2)
+	require.Eventually(t, written("first."), time.Second, 10*time.Millisecond)
+	win(1, 1)
+	win(1, 2)
+	// The leader of term 2 stepped down and robot 2 took over, the secret was already delivered
+	win(2, 3)
+	time.Sleep(50 * time.Millisecond)
+	ass.True(written("first.")(), "neither the winner of an older term nor the next leader rewrite a delivered secret")
+
+	var second []events.SecondWinnerEvent
+	for len(domainEvent) > 0 {
+		if event := <-domainEvent; event.EventType == events.EventSecondWinner {
+			second = append(second, event.Payload.(events.SecondWinnerEvent))
+		}
+	}
+	ass.Equal([]events.SecondWinnerEvent{{Winner: 0, Other: 1}}, second, "only another winner of the same term is a second winner")
+}
+
+// TestConvergenceDetector_VerificationFailureIsTerminal A wrong part is never replaced, the robot can't complete
+func TestConvergenceDetector_VerificationFailureIsTerminal(t *testing.T) {
+	ass := assert.New(t)
+	cfg := conf.Config{EndOfSecret: ".", QuietPeriod: time.Millisecond}
+	integrity := robot.NewSecretIntegrity([]string{"hello", "world."})
+	corrupted := robot.NewRobot(0, integrity, robot.SecretPart{Index: 0, Word: "hello"}, robot.SecretPart{Index: 1, Word: "there."})
+	eventsCh := make(chan events.Event, 10)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ass.NoError(workers.NewConvergenceDetectorWorker(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), corrupted, eventsCh).Run(ctx))
+	ass.NoError(ctx.Err(), "the worker stops on its own")
+	ass.Len(eventsCh, 1)
+	ass.Equal(events.EventSecretVerificationFailed, (<-eventsCh).EventType, "reported once, never as a winner")
+}
+
+func sink(t *testing.T, cfg conf.Config) output.Fanout {
+	fanout, err := output.NewSink(cfg)
+	require.NoError(t, err)
+	return fanout
+}
+
+// handleEvents Hands the domain events to the handlers, like the event fanout worker
+func handleEvents(ctx context.Context, eventsCh chan events.Event, handlers ...events.EventHandler) {
+	for {
+		select {
+		case event := <-eventsCh:
+			for _, handler := range handlers {
+				handler.Handle(event)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}